@@ -6,8 +6,18 @@ import (
 	"time"
 
 	"github.com/amilcar-vasquez/auth-service/backend/config"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/achievements"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/dataexport"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/gitea"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/gitlab"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/mailer"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/providers"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/refresh"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/retention"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/snapshots"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
 	"github.com/amilcar-vasquez/auth-service/backend/routes"
 	"github.com/go-chi/chi/v5"
@@ -20,8 +30,22 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
-	// Initialize JWT utilities
+	// Initialize JWT utilities and how long sudo mode lasts
 	utils.InitJWT(cfg.JWTSecret)
+	middleware.SetSudoWindow(time.Duration(cfg.SudoModeMinutes) * time.Minute)
+
+	// Configure self-hosted forge instances and language breakdowns
+	github.SetEnterpriseHosts(cfg.GithubEnterpriseHosts)
+	providers.SetAllowedHosts(providers.GitLab, cfg.GitlabHosts)
+	providers.SetAllowedHosts(providers.Gitea, cfg.GiteaHosts)
+	github.SetIgnoredLanguages(cfg.IgnoredLanguages)
+
+	// Load custom achievement rules
+	if cfg.AchievementsFile != "" {
+		if err := achievements.LoadRulesFile(cfg.AchievementsFile); err != nil {
+			log.Fatalf("Failed to load achievement rules: %v", err)
+		}
+	}
 
 	// Connect to database
 	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{})
@@ -29,8 +53,13 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Email uniqueness ignores deleted accounts; drop the old full-table
+	// constraint and index so they don't block re-registration
+	db.Exec("ALTER TABLE IF EXISTS users DROP CONSTRAINT IF EXISTS users_email_key")
+	db.Exec("DROP INDEX IF EXISTS idx_users_email")
+
 	// Auto-migrate database schema
-	if err := db.AutoMigrate(&models.User{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.GithubSnapshot{}, &models.GithubAccount{}, &models.WebhookDelivery{}, &models.Achievement{}, &models.Team{}, &models.TeamMember{}, &models.TeamInvite{}, &models.DataExport{}, &models.ConfirmationToken{}); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 	log.Println("✓ Database migration completed")
@@ -51,8 +80,49 @@ func main() {
 	// Add logger middleware
 	router.Use(middleware.Logger)
 
+	// Create forge API clients
+	forgeHTTPClient := &http.Client{Timeout: 20 * time.Second}
+	registry := providers.Registry{
+		providers.GitHub: github.NewClient(cfg.GithubGraphQLURL, forgeHTTPClient),
+		providers.GitLab: gitlab.NewClient(forgeHTTPClient),
+		providers.Gitea:  gitea.NewClient(forgeHTTPClient),
+	}
+
+	// Refresh snapshots in the background when webhooks report new activity
+	scheduler := refresh.NewScheduler(db, registry, refresh.DefaultDelay)
+	go scheduler.Run()
+
+	// Keep one snapshot per day for recent history and one per month after that
+	go snapshots.Run(db)
+
+	// Purge deleted accounts once their grace period ends
+	retention.SetGracePeriod(time.Duration(cfg.AccountDeletionGraceDays) * 24 * time.Hour)
+	go retention.Run(db)
+
+	// Assemble personal data exports in the background, removing expired archives
+	linkKey, err := dataexport.LinkKey(cfg.JWTSecret)
+	if err != nil {
+		log.Fatalf("Failed to derive the data export link key: %v", err)
+	}
+	exporter := dataexport.NewExporter(db, cfg.DataExportDir, linkKey, dataexport.DefaultTTL)
+	go exporter.Run()
+
+	// Send email through SMTP when configured, otherwise log it
+	var mail mailer.Mailer = mailer.LogMailer{LogBodies: cfg.MailLogBodies}
+	if cfg.SMTPHost == "" {
+		log.Println("Warning: SMTP_HOST is not set, so emails are only logged and never delivered")
+	} else {
+		mail = mailer.SMTPMailer{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}
+	}
+
 	// Setup routes after middleware
-	routes.SetupRoutes(router, db)
+	routes.SetupRoutes(router, db, registry, scheduler, exporter, mail, cfg.GithubWebhookSecret)
 
 	// Start server
 	server := &http.Server{
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +16,44 @@ type Config struct {
 	DatabaseURL string
 	JWTSecret   string
 	CORSOrigin  string
+
+	// GitHub GraphQL API endpoint
+	GithubGraphQLURL string
+
+	// GitHub Enterprise Server hosts users may connect accounts on
+	GithubEnterpriseHosts []string
+
+	// Self-hosted GitLab and Gitea instances users may connect accounts on
+	GitlabHosts []string
+	GiteaHosts  []string
+
+	// Secret GitHub webhook deliveries are signed with
+	GithubWebhookSecret string
+
+	// Languages left out of GitHub language breakdowns (e.g. HTML, Jupyter Notebook)
+	IgnoredLanguages []string
+
+	// Optional JSON file replacing the built-in achievement rules
+	AchievementsFile string
+
+	// Directory personal data export archives are written to
+	DataExportDir string
+
+	// Days a deleted account can be restored before it is purged
+	AccountDeletionGraceDays int
+
+	// Minutes after entering their password that users may perform sensitive actions
+	SudoModeMinutes int
+
+	// SMTP server for outgoing email; emails are logged when no host is set
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+
+	// Log email bodies, confirmation codes included, when no SMTP host is set
+	MailLogBodies bool
 }
 
 // Load reads configuration from environment variables
@@ -25,6 +66,26 @@ func Load() *Config {
 		DatabaseURL: getEnv("DATABASE_URL", ""),
 		JWTSecret:   getEnv("JWT_SECRET", ""),
 		CORSOrigin:  getEnv("CORS_ORIGIN", "http://localhost:5173"),
+
+		GithubGraphQLURL:      getEnv("GITHUB_GRAPHQL_URL", "https://api.github.com/graphql"),
+		GithubEnterpriseHosts: getEnvList("GITHUB_ENTERPRISE_HOSTS"),
+		GitlabHosts:           getEnvList("GITLAB_HOSTS"),
+		GiteaHosts:            getEnvList("GITEA_HOSTS"),
+		GithubWebhookSecret:   getEnv("GITHUB_WEBHOOK_SECRET", ""),
+		IgnoredLanguages:      getEnvList("GITHUB_IGNORED_LANGUAGES"),
+		AchievementsFile:      getEnv("ACHIEVEMENTS_FILE", ""),
+		DataExportDir:         getEnv("DATA_EXPORT_DIR", filepath.Join(os.TempDir(), "data-exports")),
+
+		AccountDeletionGraceDays: getEnvInt("ACCOUNT_DELETION_GRACE_DAYS", 30),
+		SudoModeMinutes:          getEnvInt("SUDO_MODE_MINUTES", 10),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		MailFrom:     getEnv("MAIL_FROM", "no-reply@localhost"),
+
+		MailLogBodies: getEnvBool("MAIL_LOG_BODIES", false),
 	}
 
 	// Validate required config
@@ -34,6 +95,9 @@ func Load() *Config {
 	if cfg.JWTSecret == "" {
 		log.Fatal("JWT_SECRET environment variable is required")
 	}
+	if cfg.SudoModeMinutes <= 0 {
+		log.Fatal("SUDO_MODE_MINUTES must be a positive number of minutes")
+	}
 
 	return cfg
 }
@@ -45,3 +109,32 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt retrieves an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvBool retrieves a boolean environment variable or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvList retrieves a comma-separated environment variable as a list
+func getEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,22 @@
+-- Point-in-time copies of users' GitHub statistics
+-- Used for percentile ranking and leaderboards without live GitHub calls
+
+CREATE TABLE IF NOT EXISTS github_snapshots (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    login TEXT,
+    commits INTEGER NOT NULL DEFAULT 0,
+    pull_requests INTEGER NOT NULL DEFAULT 0,
+    issues INTEGER NOT NULL DEFAULT 0,
+    reviews INTEGER NOT NULL DEFAULT 0,
+    stars INTEGER NOT NULL DEFAULT 0,
+    followers INTEGER NOT NULL DEFAULT 0,
+    score INTEGER NOT NULL DEFAULT 0,
+    rank TEXT,
+    stats JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Index for latest-snapshot lookups per user
+CREATE INDEX IF NOT EXISTS idx_github_snapshots_user_id ON github_snapshots(user_id);
+CREATE INDEX IF NOT EXISTS idx_github_snapshots_created_at ON github_snapshots(created_at);
@@ -0,0 +1,3 @@
+-- Opt-in flag for the team leaderboard
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS leaderboard_opt_in BOOLEAN NOT NULL DEFAULT FALSE;
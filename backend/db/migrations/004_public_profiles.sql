@@ -0,0 +1,7 @@
+-- Public profile handles and privacy settings
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS handle TEXT UNIQUE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS privacy_public BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS privacy_show_email BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS privacy_show_github_stats BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS privacy_show_rank BOOLEAN NOT NULL DEFAULT FALSE;
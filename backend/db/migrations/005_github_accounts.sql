@@ -0,0 +1,16 @@
+-- GitHub Enterprise Server support and additional connected accounts
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS github_host TEXT;
+
+CREATE TABLE IF NOT EXISTS github_accounts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    host TEXT NOT NULL DEFAULT 'github.com',
+    username TEXT NOT NULL,
+    token TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Index for per-user account lookups
+CREATE INDEX IF NOT EXISTS idx_github_accounts_user_id ON github_accounts(user_id);
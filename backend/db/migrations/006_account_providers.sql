@@ -0,0 +1,3 @@
+-- GitLab and Gitea accounts alongside GitHub
+
+ALTER TABLE github_accounts ADD COLUMN IF NOT EXISTS provider TEXT NOT NULL DEFAULT 'github';
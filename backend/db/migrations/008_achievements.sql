@@ -0,0 +1,12 @@
+-- Achievements awarded from GitHub snapshots
+
+CREATE TABLE IF NOT EXISTS achievements (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    rule_id TEXT NOT NULL,
+    snapshot_id INTEGER REFERENCES github_snapshots(id),
+    awarded_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Each achievement is awarded once per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_achievement ON achievements(user_id, rule_id);
@@ -0,0 +1,4 @@
+-- Opt-in private contribution counting
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS privacy_include_private_contributions BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS privacy_show_private_contributions BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,13 @@
+-- One GitHub snapshot per user per day
+
+ALTER TABLE github_snapshots ADD COLUMN IF NOT EXISTS day DATE;
+
+-- Earlier snapshots of the same day keep a NULL day and are removed by pruning
+UPDATE github_snapshots SET day = created_at::date
+WHERE id IN (
+    SELECT DISTINCT ON (user_id, created_at::date) id
+    FROM github_snapshots
+    ORDER BY user_id, created_at::date, created_at DESC
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_github_snapshots_user_day ON github_snapshots(user_id, day);
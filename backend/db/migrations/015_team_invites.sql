@@ -0,0 +1,15 @@
+-- Invitations to join a team, accepted by the invitee
+
+CREATE TABLE IF NOT EXISTS team_invites (
+    id SERIAL PRIMARY KEY,
+    team_id INTEGER NOT NULL REFERENCES teams(id),
+    email TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'member',
+    invited_by INTEGER NOT NULL REFERENCES users(id),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Each email has one pending invitation per team
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_invite ON team_invites(team_id, email);
+CREATE INDEX IF NOT EXISTS idx_team_invites_email ON team_invites(email);
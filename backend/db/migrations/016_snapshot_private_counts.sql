@@ -0,0 +1,14 @@
+-- Private contribution counts kept apart from what other users can see
+
+ALTER TABLE github_snapshots ADD COLUMN IF NOT EXISTS private_commits INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE github_snapshots ADD COLUMN IF NOT EXISTS private_pull_requests INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE github_snapshots ADD COLUMN IF NOT EXISTS private_issues INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE github_snapshots ADD COLUMN IF NOT EXISTS private_reviews INTEGER NOT NULL DEFAULT 0;
+
+-- Backfill from the breakdown stored with snapshots that counted private contributions
+UPDATE github_snapshots SET
+    private_commits = COALESCE((stats->'private'->>'commits')::int, 0) + COALESCE((stats->'private'->>'restricted')::int, 0),
+    private_pull_requests = COALESCE((stats->'private'->>'pull_requests')::int, 0),
+    private_issues = COALESCE((stats->'private'->>'issues')::int, 0),
+    private_reviews = COALESCE((stats->'private'->>'reviews')::int, 0)
+WHERE (stats->>'includes_private')::boolean;
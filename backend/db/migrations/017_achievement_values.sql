@@ -0,0 +1,11 @@
+-- Keep the values an achievement was awarded for, since same-day refreshes
+-- overwrite the snapshot it points to
+
+ALTER TABLE achievements ADD COLUMN IF NOT EXISTS value INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE achievements ADD COLUMN IF NOT EXISTS score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE achievements ADD COLUMN IF NOT EXISTS rank TEXT;
+
+-- Best effort for existing awards: the referenced snapshot's current score and rank
+UPDATE achievements a SET score = s.score, rank = s.rank
+FROM github_snapshots s
+WHERE s.id = a.snapshot_id AND a.rank IS NULL;
@@ -0,0 +1,4 @@
+-- Invitations are accepted with a single-use token emailed to the invitee.
+-- Pending invitations without one can't be accepted and need to be re-sent.
+
+ALTER TABLE team_invites ADD COLUMN IF NOT EXISTS token_hash TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,250 @@
+// Package achievements awards badges for GitHub activity. Achievements are
+// defined as data rules evaluated against each stored snapshot.
+package achievements
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/snapshots"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:embed rules.json
+var defaultRules []byte
+
+// Rule awards an achievement once a metric reaches a threshold
+type Rule struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Metric      string `json:"metric"`
+	Threshold   int    `json:"threshold"`
+	// WindowDays measures the metric's growth over the last N days instead of
+	// its current value; only scored metrics support windows
+	WindowDays int `json:"window_days,omitempty"`
+}
+
+// Metrics available to rules in addition to the scored rank metrics
+const (
+	MetricContributions      = "contributions"
+	MetricCurrentStreak      = "current_streak"
+	MetricLongestStreak      = "longest_streak"
+	MetricPublicRepositories = "public_repositories"
+)
+
+// rules are the active achievement rules
+var rules = mustParseRules(defaultRules)
+
+// ParseRules parses and validates a JSON list of rules
+func ParseRules(data []byte) ([]Rule, error) {
+	var list []Rule
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(list))
+	for _, rule := range list {
+		if rule.ID == "" || rule.Name == "" {
+			return nil, errors.New("achievement rules need an id and a name")
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("duplicate achievement rule %q", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		if rule.Threshold <= 0 || rule.WindowDays < 0 {
+			return nil, fmt.Errorf("achievement rule %q needs a positive threshold and window", rule.ID)
+		}
+		_, scored := github.MetricValue(github.UserProfileStats{}, rule.Metric)
+		if !scored && !extraMetric(rule.Metric) {
+			return nil, fmt.Errorf("achievement rule %q uses unknown metric %q", rule.ID, rule.Metric)
+		}
+		if rule.WindowDays > 0 && !scored {
+			return nil, fmt.Errorf("achievement rule %q can't use a window with metric %q", rule.ID, rule.Metric)
+		}
+	}
+	return list, nil
+}
+
+// mustParseRules parses the built-in rules
+func mustParseRules(data []byte) []Rule {
+	list, err := ParseRules(data)
+	if err != nil {
+		panic("invalid built-in achievement rules: " + err.Error())
+	}
+	return list
+}
+
+// SetRules replaces the active achievement rules
+func SetRules(list []Rule) {
+	rules = list
+}
+
+// LoadRulesFile replaces the active achievement rules with those in a JSON file
+func LoadRulesFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	list, err := ParseRules(data)
+	if err != nil {
+		return err
+	}
+	SetRules(list)
+	return nil
+}
+
+// extraMetric reports whether a metric is one of the non-scored rule metrics
+func extraMetric(metric string) bool {
+	switch metric {
+	case MetricContributions, MetricCurrentStreak, MetricLongestStreak, MetricPublicRepositories:
+		return true
+	}
+	return false
+}
+
+// Progress is a rule along with the user's progress towards it
+type Progress struct {
+	Rule
+	Value     int        `json:"value"`
+	Earned    bool       `json:"earned"`
+	AwardedAt *time.Time `json:"awarded_at"`
+}
+
+// Evaluate awards any achievements the snapshot meets that the user hasn't
+// earned yet, returning the newly awarded ones
+func Evaluate(db *gorm.DB, snapshot *models.GithubSnapshot) ([]models.Achievement, error) {
+	values, err := ruleValues(db, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	awarded := make([]models.Achievement, 0)
+	for _, rule := range rules {
+		if values[rule.ID] < rule.Threshold {
+			continue
+		}
+
+		achievement := models.Achievement{
+			UserID:     snapshot.UserID,
+			RuleID:     rule.ID,
+			SnapshotID: snapshot.ID,
+			AwardedAt:  snapshot.CreatedAt,
+			Value:      values[rule.ID],
+			Score:      snapshot.Score,
+			Rank:       snapshot.Rank,
+		}
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&achievement)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			awarded = append(awarded, achievement)
+		}
+	}
+	return awarded, nil
+}
+
+// List returns the user's progress towards every active rule. Achievements
+// stay earned even if a rule's metric later drops below its threshold.
+func List(db *gorm.DB, userID uint) ([]Progress, error) {
+	var earned []models.Achievement
+	if err := db.Where("user_id = ?", userID).Find(&earned).Error; err != nil {
+		return nil, err
+	}
+	awardedAt := make(map[string]time.Time, len(earned))
+	for _, a := range earned {
+		awardedAt[a.RuleID] = a.AwardedAt
+	}
+
+	values := map[string]int{}
+	snapshot, err := snapshots.Latest(db, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if snapshot != nil {
+		if values, err = ruleValues(db, snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	list := make([]Progress, 0, len(rules))
+	for _, rule := range rules {
+		progress := Progress{Rule: rule, Value: values[rule.ID]}
+		if at, ok := awardedAt[rule.ID]; ok {
+			progress.Earned = true
+			progress.AwardedAt = &at
+		}
+		list = append(list, progress)
+	}
+	return list, nil
+}
+
+// ruleValues computes each rule's metric for a snapshot
+func ruleValues(db *gorm.DB, snapshot *models.GithubSnapshot) (map[string]int, error) {
+	stats, err := snapshots.Decode(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	analytics := github.AnalyzeContributions(stats.ContributionCalendar)
+
+	values := make(map[string]int, len(rules))
+	windows := make(map[int]github.UserProfileStats)
+	for _, rule := range rules {
+		if rule.WindowDays == 0 {
+			values[rule.ID] = metricValue(*stats, analytics, rule.Metric)
+			continue
+		}
+
+		delta, ok := windows[rule.WindowDays]
+		if !ok {
+			if delta, err = windowDelta(db, snapshot, rule.WindowDays); err != nil {
+				return nil, err
+			}
+			windows[rule.WindowDays] = delta
+		}
+		values[rule.ID], _ = github.MetricValue(delta, rule.Metric)
+	}
+	return values, nil
+}
+
+// metricValue returns a rule metric from a snapshot's stats
+func metricValue(stats github.UserProfileStats, analytics github.ContributionAnalytics, metric string) int {
+	switch metric {
+	case MetricContributions:
+		return stats.ContributionCalendar.TotalContributions
+	case MetricCurrentStreak:
+		return analytics.CurrentStreak
+	case MetricLongestStreak:
+		return analytics.LongestStreak
+	case MetricPublicRepositories:
+		return stats.TotalPublicRepositories
+	}
+	value, _ := github.MetricValue(stats, metric)
+	return value
+}
+
+// windowDelta returns the growth of the scored metrics over the days before
+// the snapshot, measured from the earliest snapshot in that window. Without
+// an earlier snapshot there is nothing to measure and the delta is zero.
+func windowDelta(db *gorm.DB, snapshot *models.GithubSnapshot, days int) (github.UserProfileStats, error) {
+	start := snapshot.CreatedAt.AddDate(0, 0, -days)
+	baselines, err := snapshots.EarliestSince(db, []uint{snapshot.UserID}, start)
+	if err != nil {
+		return github.UserProfileStats{}, err
+	}
+
+	baseline, ok := baselines[snapshot.UserID]
+	if !ok || baseline.ID == snapshot.ID || !baseline.CreatedAt.Before(snapshot.CreatedAt) {
+		return github.UserProfileStats{}, nil
+	}
+	return snapshots.Delta(*snapshot, &baseline, start), nil
+}
@@ -0,0 +1,223 @@
+package cards
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strconv"
+	"text/template"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+)
+
+// Card dimensions
+const (
+	cardWidth       = 420
+	rankCardHeight  = 150
+	statsCardHeight = 195
+	progressWidth   = 260
+)
+
+// cardFuncs are the helper functions available to card templates
+var cardFuncs = template.FuncMap{
+	"esc": html.EscapeString,
+	"add": func(a, b int) int { return a + b },
+	"mul": func(a, b int) int { return a * b },
+	"div": func(a, b int) int { return a / b },
+	"mod": func(a, b int) int { return a % b },
+	"num": formatNumber,
+}
+
+var rankCardTemplate = template.Must(template.New("rank").Funcs(cardFuncs).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" role="img" aria-label="{{esc .Title}}: {{esc .Rank.Rank}}">
+  <title>{{esc .Title}}: {{esc .Rank.Rank}}</title>
+  <rect x="0.5" y="0.5" rx="6" width="{{add .Width -1}}" height="{{add .Height -1}}" fill="{{.Theme.Background}}" stroke="{{.Theme.Border}}"/>
+  <text x="25" y="35" font-family="Segoe UI, Ubuntu, sans-serif" font-size="18" font-weight="600" fill="{{.Theme.Title}}">{{esc .Title}}</text>
+  <circle cx="{{add .Width -65}}" cy="75" r="40" fill="none" stroke="{{.Theme.Track}}" stroke-width="6"/>
+  <text x="{{add .Width -65}}" y="85" text-anchor="middle" font-family="Segoe UI, Ubuntu, sans-serif" font-size="28" font-weight="700" fill="{{.Theme.Accent}}">{{esc .Rank.Rank}}</text>
+  <text x="25" y="70" font-family="Segoe UI, Ubuntu, sans-serif" font-size="14" fill="{{.Theme.Text}}">Score: {{num .Rank.Score}}</text>
+  {{- if .Rank.NextRank}}
+  <text x="25" y="92" font-family="Segoe UI, Ubuntu, sans-serif" font-size="12" fill="{{.Theme.Muted}}">{{.Rank.ProgressPercent}}% to {{esc .Rank.NextRank}} ({{num .Rank.NextRankThreshold}})</text>
+  {{- else}}
+  <text x="25" y="92" font-family="Segoe UI, Ubuntu, sans-serif" font-size="12" fill="{{.Theme.Muted}}">Top rank reached</text>
+  {{- end}}
+  <rect x="25" y="108" rx="4" width="{{.ProgressWidth}}" height="8" fill="{{.Theme.Track}}"/>
+  <rect x="25" y="108" rx="4" width="{{.ProgressFill}}" height="8" fill="{{.Theme.Accent}}"/>
+</svg>
+`))
+
+var statsCardTemplate = template.Must(template.New("stats").Funcs(cardFuncs).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" role="img" aria-label="{{esc .Title}}">
+  <title>{{esc .Title}}</title>
+  <rect x="0.5" y="0.5" rx="6" width="{{add .Width -1}}" height="{{add .Height -1}}" fill="{{.Theme.Background}}" stroke="{{.Theme.Border}}"/>
+  <text x="25" y="35" font-family="Segoe UI, Ubuntu, sans-serif" font-size="18" font-weight="600" fill="{{.Theme.Title}}">{{esc .Title}}</text>
+  {{- range $i, $row := .Rows}}
+  <text x="25" y="{{add 65 (mul $i 22)}}" font-family="Segoe UI, Ubuntu, sans-serif" font-size="14" fill="{{$.Theme.Text}}">{{esc $row.Label}}:</text>
+  <text x="230" y="{{add 65 (mul $i 22)}}" font-family="Segoe UI, Ubuntu, sans-serif" font-size="14" font-weight="700" fill="{{$.Theme.Text}}">{{num $row.Value}}</text>
+  {{- end}}
+  <text x="{{add .Width -65}}" y="110" text-anchor="middle" font-family="Segoe UI, Ubuntu, sans-serif" font-size="28" font-weight="700" fill="{{.Theme.Accent}}">{{esc .Rank.Rank}}</text>
+</svg>
+`))
+
+var languagesCardTemplate = template.Must(template.New("languages").Funcs(cardFuncs).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" role="img" aria-label="{{esc .Title}}">
+  <title>{{esc .Title}}</title>
+  <rect x="0.5" y="0.5" rx="6" width="{{add .Width -1}}" height="{{add .Height -1}}" fill="{{.Theme.Background}}" stroke="{{.Theme.Border}}"/>
+  <text x="25" y="35" font-family="Segoe UI, Ubuntu, sans-serif" font-size="18" font-weight="600" fill="{{.Theme.Title}}">{{esc .Title}}</text>
+  <rect x="25" y="52" rx="4" width="{{.BarWidth}}" height="8" fill="{{.Theme.Track}}"/>
+  {{- range .Segments}}
+  <rect x="{{.X}}" y="52" width="{{.Width}}" height="8" fill="{{.Color}}"/>
+  {{- end}}
+  {{- range $i, $lang := .Languages}}
+  <circle cx="{{add 30 (mul (mod $i 2) 185)}}" cy="{{add 81 (mul (div $i 2) 22)}}" r="5" fill="{{$lang.Color}}"/>
+  <text x="{{add 42 (mul (mod $i 2) 185)}}" y="{{add 86 (mul (div $i 2) 22)}}" font-family="Segoe UI, Ubuntu, sans-serif" font-size="12" fill="{{$.Theme.Text}}">{{esc $lang.Name}} {{$lang.SharePercent}}%</text>
+  {{- end}}
+</svg>
+`))
+
+var messageCardTemplate = template.Must(template.New("message").Funcs(cardFuncs).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" role="img" aria-label="{{esc .Message}}">
+  <rect x="0.5" y="0.5" rx="6" width="{{add .Width -1}}" height="{{add .Height -1}}" fill="{{.Theme.Background}}" stroke="{{.Theme.Border}}"/>
+  <text x="25" y="45" font-family="Segoe UI, Ubuntu, sans-serif" font-size="14" fill="{{.Theme.Muted}}">{{esc .Message}}</text>
+</svg>
+`))
+
+// statRow is a labelled value on the stats card
+type statRow struct {
+	Label string
+	Value int
+}
+
+// RenderRankCard renders the rank tier, score and progress bar as an SVG card
+func RenderRankCard(title string, rank github.RankInfo, theme Theme) ([]byte, error) {
+	data := struct {
+		Width, Height int
+		Title         string
+		Rank          github.RankInfo
+		Theme         Theme
+		ProgressWidth int
+		ProgressFill  int
+	}{
+		Width:         cardWidth,
+		Height:        rankCardHeight,
+		Title:         title,
+		Rank:          rank,
+		Theme:         theme,
+		ProgressWidth: progressWidth,
+		ProgressFill:  progressWidth * rank.ProgressPercent / 100,
+	}
+	return render(rankCardTemplate, data)
+}
+
+// RenderStatsCard renders the key profile statistics as an SVG card
+func RenderStatsCard(title string, stats github.UserProfileStats, rank github.RankInfo, theme Theme) ([]byte, error) {
+	data := struct {
+		Width, Height int
+		Title         string
+		Rows          []statRow
+		Rank          github.RankInfo
+		Theme         Theme
+	}{
+		Width:  cardWidth,
+		Height: statsCardHeight,
+		Title:  title,
+		Rows: []statRow{
+			{"Total Commits", stats.TotalCommits},
+			{"Pull Requests", stats.TotalPullRequests},
+			{"Reviews", stats.TotalReviews},
+			{"Issues", stats.TotalIssues},
+			{"Stars Earned", stats.TotalStarsEarned},
+			{"Followers", stats.Followers},
+		},
+		Rank:  rank,
+		Theme: theme,
+	}
+	return render(statsCardTemplate, data)
+}
+
+// languageSegment is a colored section of the languages bar
+type languageSegment struct {
+	X, Width int
+	Color    string
+}
+
+// hexColor matches the CSS hex colors accepted from language data
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{3,8}$`)
+
+// RenderLanguagesCard renders the user's top languages as a stacked bar with a legend
+func RenderLanguagesCard(title string, languages []github.LanguageStat, theme Theme) ([]byte, error) {
+	barWidth := cardWidth - 50
+
+	// Lay out bar segments proportionally to each language's share
+	segments := make([]languageSegment, 0, len(languages))
+	x := 25
+	for _, lang := range languages {
+		width := int(float64(barWidth) * lang.SharePercent / 100)
+		// Colors come from upstream data and are written into attributes unescaped
+		color := lang.Color
+		if !hexColor.MatchString(color) {
+			color = theme.Muted
+		}
+		segments = append(segments, languageSegment{X: x, Width: width, Color: color})
+		x += width
+	}
+
+	// Fall back to the muted color in the legend as well
+	legend := make([]github.LanguageStat, len(languages))
+	for i, lang := range languages {
+		legend[i] = lang
+		legend[i].Color = segments[i].Color
+	}
+
+	data := struct {
+		Width, Height int
+		BarWidth      int
+		Title         string
+		Segments      []languageSegment
+		Languages     []github.LanguageStat
+		Theme         Theme
+	}{
+		Width:     cardWidth,
+		Height:    80 + ((len(languages)+1)/2)*22,
+		BarWidth:  barWidth,
+		Title:     title,
+		Segments:  segments,
+		Languages: legend,
+		Theme:     theme,
+	}
+	return render(languagesCardTemplate, data)
+}
+
+// RenderMessageCard renders a card containing a single message, used when
+// there is no data to show
+func RenderMessageCard(message string, theme Theme) ([]byte, error) {
+	data := struct {
+		Width, Height int
+		Message       string
+		Theme         Theme
+	}{
+		Width:   cardWidth,
+		Height:  80,
+		Message: message,
+		Theme:   theme,
+	}
+	return render(messageCardTemplate, data)
+}
+
+// render executes a card template into a byte slice
+func render(tmpl *template.Template, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// formatNumber abbreviates large numbers (e.g. 1500 becomes 1.5k)
+func formatNumber(n int) string {
+	if n >= 1000 {
+		whole := n / 1000
+		tenth := (n % 1000) / 100
+		if tenth == 0 || whole >= 100 {
+			return strconv.Itoa(whole) + "k"
+		}
+		return strconv.Itoa(whole) + "." + strconv.Itoa(tenth) + "k"
+	}
+	return strconv.Itoa(n)
+}
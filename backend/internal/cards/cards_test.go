@@ -0,0 +1,28 @@
+package cards
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+)
+
+func TestRenderLanguagesCardRejectsUnsafeColors(t *testing.T) {
+	theme := GetTheme("")
+	languages := []github.LanguageStat{
+		{Name: "Go", Color: "#00ADD8", SharePercent: 60},
+		{Name: "Evil", Color: `red"/><script>alert(1)</script><rect fill="`, SharePercent: 40},
+	}
+
+	svg, err := RenderLanguagesCard("Top Languages", languages, theme)
+	if err != nil {
+		t.Fatalf("RenderLanguagesCard: %v", err)
+	}
+	out := string(svg)
+	if strings.Contains(out, "<script>") {
+		t.Errorf("card contains injected markup: %s", out)
+	}
+	if !strings.Contains(out, `fill="#00ADD8"`) || !strings.Contains(out, `fill="`+theme.Muted+`"`) {
+		t.Errorf("card colors = %s", out)
+	}
+}
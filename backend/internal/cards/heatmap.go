@@ -0,0 +1,230 @@
+package cards
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+)
+
+// Heatmap cell size limits in pixels
+const (
+	DefaultCellSize = 11
+	MinCellSize     = 4
+	MaxCellSize     = 32
+)
+
+// MaxHeatmapDays is the longest range a heatmap shows, which bounds the size
+// of the rendered image
+const MaxHeatmapDays = 366
+
+// ColorScales lists the available heatmap color scales, from no
+// contributions to the highest activity level
+var ColorScales = map[string][5]string{
+	"github": {"#ebedf0", "#9be9a8", "#40c463", "#30a14e", "#216e39"},
+	"blue":   {"#ebedf0", "#c6dbef", "#6baed6", "#2171b5", "#08306b"},
+	"purple": {"#ebedf0", "#d4c4fb", "#a78bfa", "#7c3aed", "#4c1d95"},
+	"orange": {"#ebedf0", "#ffd8a8", "#ffa94d", "#f76707", "#a83c00"},
+	"dark":   {"#161b22", "#0e4429", "#006d32", "#26a641", "#39d353"},
+}
+
+// DefaultColorScale is used when no color scale or an unknown one is requested
+const DefaultColorScale = "github"
+
+// HeatmapOptions controls how a contribution heatmap is rendered
+type HeatmapOptions struct {
+	Scale    string
+	CellSize int
+	From     time.Time // Zero means the start of the calendar
+	To       time.Time // Zero means the end of the calendar
+}
+
+// heatmapCell is a single positioned day in the heatmap grid
+type heatmapCell struct {
+	column, row int
+	level       int
+	day         github.ContributionDay
+}
+
+// heatmapLayout is the grid computed from a contribution calendar
+type heatmapLayout struct {
+	cells   []heatmapCell
+	columns int
+	gap     int
+	cell    int
+	colors  [5]string
+}
+
+// width returns the layout width in pixels
+func (l heatmapLayout) width() int {
+	return l.columns*(l.cell+l.gap) + l.gap
+}
+
+// height returns the layout height in pixels
+func (l heatmapLayout) height() int {
+	return 7*(l.cell+l.gap) + l.gap
+}
+
+// layoutHeatmap places each calendar day on a week/weekday grid and assigns
+// it one of five activity levels relative to the busiest day in range
+func layoutHeatmap(calendar github.ContributionCalendar, opts HeatmapOptions) heatmapLayout {
+	colors, ok := ColorScales[opts.Scale]
+	if !ok {
+		colors = ColorScales[DefaultColorScale]
+	}
+
+	size := opts.CellSize
+	if size == 0 {
+		size = DefaultCellSize
+	}
+	if size < MinCellSize {
+		size = MinCellSize
+	} else if size > MaxCellSize {
+		size = MaxCellSize
+	}
+
+	layout := heatmapLayout{
+		gap:    max(1, size/5),
+		cell:   size,
+		colors: colors,
+	}
+
+	// Collect days in range
+	var days []github.ContributionDay
+	maxCount := 0
+	for _, week := range calendar.Weeks {
+		for _, day := range week.ContributionDays {
+			date, err := time.Parse("2006-01-02", day.Date)
+			if err != nil {
+				continue
+			}
+			if !opts.From.IsZero() && date.Before(opts.From) {
+				continue
+			}
+			if !opts.To.IsZero() && date.After(opts.To) {
+				continue
+			}
+			days = append(days, day)
+			if day.ContributionCount > maxCount {
+				maxCount = day.ContributionCount
+			}
+		}
+	}
+	if len(days) == 0 {
+		return layout
+	}
+	// Show the most recent days of longer calendars
+	if len(days) > MaxHeatmapDays {
+		days = days[len(days)-MaxHeatmapDays:]
+	}
+
+	first, _ := time.Parse("2006-01-02", days[0].Date)
+	// Align the first column to the Sunday on or before the first day
+	origin := first.AddDate(0, 0, -int(first.Weekday()))
+
+	for _, day := range days {
+		date, _ := time.Parse("2006-01-02", day.Date)
+		offset := int(date.Sub(origin).Hours() / 24)
+
+		cell := heatmapCell{
+			column: offset / 7,
+			row:    int(date.Weekday()),
+			level:  contributionLevel(day.ContributionCount, maxCount),
+			day:    day,
+		}
+		layout.cells = append(layout.cells, cell)
+		if cell.column+1 > layout.columns {
+			layout.columns = cell.column + 1
+		}
+	}
+
+	return layout
+}
+
+// contributionLevel maps a contribution count to an activity level from 0 to 4
+func contributionLevel(count, maxCount int) int {
+	if count <= 0 || maxCount <= 0 {
+		return 0
+	}
+	level := (count*4 + maxCount - 1) / maxCount
+	if level > 4 {
+		level = 4
+	}
+	return level
+}
+
+// RenderHeatmapSVG renders a contribution calendar as an SVG heatmap
+func RenderHeatmapSVG(calendar github.ContributionCalendar, opts HeatmapOptions) []byte {
+	layout := layoutHeatmap(calendar, opts)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="Contribution heatmap">`+"\n",
+		layout.width(), layout.height(), layout.width(), layout.height())
+
+	radius := max(1, layout.cell/5)
+	for _, c := range layout.cells {
+		x := layout.gap + c.column*(layout.cell+layout.gap)
+		y := layout.gap + c.row*(layout.cell+layout.gap)
+		fmt.Fprintf(&buf, `  <rect x="%d" y="%d" width="%d" height="%d" rx="%d" fill="%s"><title>%s</title></rect>`+"\n",
+			x, y, layout.cell, layout.cell, radius, layout.colors[c.level],
+			html.EscapeString(contributionLabel(c.day)))
+	}
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// RenderHeatmapPNG renders a contribution calendar as a PNG heatmap
+func RenderHeatmapPNG(calendar github.ContributionCalendar, opts HeatmapOptions) ([]byte, error) {
+	layout := layoutHeatmap(calendar, opts)
+
+	img := image.NewRGBA(image.Rect(0, 0, layout.width(), layout.height()))
+	draw.Draw(img, img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+
+	palette := make([]color.RGBA, len(layout.colors))
+	for i, hex := range layout.colors {
+		palette[i] = parseHexColor(hex)
+	}
+
+	for _, c := range layout.cells {
+		x := layout.gap + c.column*(layout.cell+layout.gap)
+		y := layout.gap + c.row*(layout.cell+layout.gap)
+		rect := image.Rect(x, y, x+layout.cell, y+layout.cell)
+		draw.Draw(img, rect, &image.Uniform{C: palette[c.level]}, image.Point{}, draw.Src)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// contributionLabel returns the tooltip text for a day
+func contributionLabel(day github.ContributionDay) string {
+	if day.ContributionCount == 1 {
+		return "1 contribution on " + day.Date
+	}
+	return strconv.Itoa(day.ContributionCount) + " contributions on " + day.Date
+}
+
+// parseHexColor parses a #rrggbb color, returning opaque black on error
+func parseHexColor(hex string) color.RGBA {
+	c := color.RGBA{A: 0xff}
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil {
+		return c
+	}
+	c.R = uint8(value >> 16)
+	c.G = uint8(value >> 8)
+	c.B = uint8(value)
+	return c
+}
@@ -0,0 +1,63 @@
+package cards
+
+// Theme defines the colors used to render a card
+type Theme struct {
+	Background string
+	Border     string
+	Title      string
+	Text       string
+	Muted      string
+	Accent     string
+	Track      string
+}
+
+// DefaultTheme is used when no theme or an unknown theme is requested
+const DefaultTheme = "light"
+
+// Themes lists the available card themes by name
+var Themes = map[string]Theme{
+	"light": {
+		Background: "#fffefe",
+		Border:     "#e4e2e2",
+		Title:      "#2f80ed",
+		Text:       "#434d58",
+		Muted:      "#7a8490",
+		Accent:     "#4c71f2",
+		Track:      "#e8ecf4",
+	},
+	"dark": {
+		Background: "#151515",
+		Border:     "#30363d",
+		Title:      "#ffffff",
+		Text:       "#9f9f9f",
+		Muted:      "#6e7681",
+		Accent:     "#79ff97",
+		Track:      "#2d333b",
+	},
+	"github": {
+		Background: "#0d1117",
+		Border:     "#30363d",
+		Title:      "#58a6ff",
+		Text:       "#c9d1d9",
+		Muted:      "#8b949e",
+		Accent:     "#3fb950",
+		Track:      "#21262d",
+	},
+	"dracula": {
+		Background: "#282a36",
+		Border:     "#44475a",
+		Title:      "#ff6e96",
+		Text:       "#f8f8f2",
+		Muted:      "#6272a4",
+		Accent:     "#bd93f9",
+		Track:      "#44475a",
+	},
+}
+
+// GetTheme returns the named theme, falling back to the default theme
+func GetTheme(name string) Theme {
+	if theme, ok := Themes[name]; ok {
+		return theme
+	}
+	return Themes[DefaultTheme]
+}
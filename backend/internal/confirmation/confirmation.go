@@ -0,0 +1,80 @@
+package confirmation
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultTTL is how long a confirmation token is valid
+const DefaultTTL = 30 * time.Minute
+
+// ErrInvalidToken is returned for unknown, used or expired tokens
+var ErrInvalidToken = errors.New("invalid or expired confirmation token")
+
+// HashToken returns the stored form of a token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewToken returns a random token and its stored form
+func NewToken() (token, hash string, err error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(raw)
+	return token, HashToken(token), nil
+}
+
+// Issue creates a token confirming an action for a user, replacing any
+// unused token for the same action
+func Issue(db *gorm.DB, userID uint, purpose string, ttl time.Duration) (string, error) {
+	token, hash, err := NewToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Delete(&models.ConfirmationToken{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.ConfirmationToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: hash,
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Consume checks a user's token for an action and marks it used
+func Consume(db *gorm.DB, userID uint, purpose, token string) error {
+	if token == "" {
+		return ErrInvalidToken
+	}
+
+	result := db.Model(&models.ConfirmationToken{}).
+		Where("user_id = ? AND purpose = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?",
+			userID, purpose, HashToken(token), time.Now()).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidToken
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package dataexport
+
+import (
+	"archive/zip"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/export"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultTTL is how long a finished export can be downloaded
+const DefaultTTL = 24 * time.Hour
+
+// cleanupInterval is how often expired archives are removed
+const cleanupInterval = time.Hour
+
+// staleAfter is when a pending export is assumed lost, such as by a restart
+const staleAfter = time.Hour
+
+// ErrInvalidSignature is returned for download links that are tampered with or expired
+var ErrInvalidSignature = errors.New("invalid or expired download link")
+
+// readme describes the archive contents to the user
+const readme = `Personal data export
+
+user.json               Your account, without your password hash or tokens
+github_accounts.json    Additional forge accounts you connected, without tokens
+github_snapshots.jsonl  Every stored snapshot of your GitHub statistics, one per line
+rank_history.csv        Your rank and scored metrics from each snapshot
+achievements.json       Achievements you were awarded
+team_memberships.json   Teams you belong to and your role in each
+data_exports.json       Earlier data exports you requested
+
+This service doesn't keep login sessions or audit events. Sign-in uses
+short-lived signed tokens that are not stored, so there is nothing to export
+for them.
+`
+
+// linkKeyLabel separates the download link key from other keys derived from
+// the same secret
+const linkKeyLabel = "export-link"
+
+// LinkKey derives the key download links are signed with from the server
+// secret, so a link signature can't be used as, or reveal, a sign-in token key
+func LinkKey(secret string) ([]byte, error) {
+	return hkdf.Key(sha256.New, []byte(secret), nil, linkKeyLabel, sha256.Size)
+}
+
+// Exporter assembles personal data archives in the background
+type Exporter struct {
+	db  *gorm.DB
+	dir string
+	key []byte
+	ttl time.Duration
+}
+
+// NewExporter creates an exporter that writes archives to dir and signs
+// download links with key
+func NewExporter(db *gorm.DB, dir string, key []byte, ttl time.Duration) *Exporter {
+	return &Exporter{db: db, dir: dir, key: key, ttl: ttl}
+}
+
+// Start queues an export for a user. An export that is still being assembled
+// is returned instead of starting another.
+func (e *Exporter) Start(userID uint) (*models.DataExport, error) {
+	var pending models.DataExport
+	err := e.db.Where("user_id = ? AND status = ? AND created_at > ?", userID, models.DataExportPending, time.Now().Add(-staleAfter)).
+		First(&pending).Error
+	if err == nil {
+		return &pending, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	record := models.DataExport{UserID: userID, Status: models.DataExportPending}
+	if err := e.db.Create(&record).Error; err != nil {
+		return nil, err
+	}
+
+	go e.build(record)
+	return &record, nil
+}
+
+// Get returns one of a user's exports
+func (e *Exporter) Get(userID, id uint) (*models.DataExport, error) {
+	var record models.DataExport
+	if err := e.db.Where("id = ? AND user_id = ?", id, userID).First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// build assembles the archive and records the outcome
+func (e *Exporter) build(record models.DataExport) {
+	path := filepath.Join(e.dir, fmt.Sprintf("export-%d-%d.zip", record.UserID, record.ID))
+	size, err := e.writeArchive(record.UserID, path)
+
+	now := time.Now()
+	updates := map[string]interface{}{"completed_at": now}
+	if err != nil {
+		log.Printf("Failed to build data export %d: %v", record.ID, err)
+		os.Remove(path)
+		updates["status"] = models.DataExportFailed
+		updates["error"] = "Failed to assemble export"
+	} else {
+		updates["status"] = models.DataExportReady
+		updates["path"] = path
+		updates["size"] = size
+		updates["expires_at"] = now.Add(e.ttl)
+	}
+
+	if err := e.db.Model(&models.DataExport{}).Where("id = ?", record.ID).Updates(updates).Error; err != nil {
+		log.Printf("Failed to update data export %d: %v", record.ID, err)
+	}
+}
+
+// writeArchive writes a user's data to a ZIP file and returns its size
+func (e *Exporter) writeArchive(userID uint, path string) (int64, error) {
+	if err := os.MkdirAll(e.dir, 0o700); err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	if err := e.writeEntries(archive, userID); err != nil {
+		return 0, err
+	}
+	if err := archive.Close(); err != nil {
+		return 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// writeEntries adds each part of a user's data to the archive
+func (e *Exporter) writeEntries(archive *zip.Writer, userID uint) error {
+	var user models.User
+	if err := e.db.Unscoped().First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	var accounts []models.GithubAccount
+	if err := e.db.Where("user_id = ?", userID).Order("id").Find(&accounts).Error; err != nil {
+		return err
+	}
+	var awarded []models.Achievement
+	if err := e.db.Where("user_id = ?", userID).Order("awarded_at").Find(&awarded).Error; err != nil {
+		return err
+	}
+	var memberships []models.TeamMember
+	if err := e.db.Where("user_id = ?", userID).Order("id").Find(&memberships).Error; err != nil {
+		return err
+	}
+	var exports []models.DataExport
+	if err := e.db.Where("user_id = ?", userID).Order("id").Find(&exports).Error; err != nil {
+		return err
+	}
+
+	entries := []struct {
+		name  string
+		write func(io.Writer) error
+	}{
+		{"README.txt", func(w io.Writer) error { _, err := io.WriteString(w, readme); return err }},
+		{"user.json", writeJSON(user)},
+		{"github_accounts.json", writeJSON(accounts)},
+		{"github_snapshots.jsonl", func(w io.Writer) error { return e.writeSnapshots(w, userID) }},
+		{"rank_history.csv", func(w io.Writer) error { return e.writeRankHistory(w, userID) }},
+		{"achievements.json", writeJSON(awarded)},
+		{"team_memberships.json", writeJSON(memberships)},
+		{"data_exports.json", writeJSON(exports)},
+	}
+
+	for _, entry := range entries {
+		w, err := archive.Create(entry.name)
+		if err != nil {
+			return err
+		}
+		if err := entry.write(w); err != nil {
+			return fmt.Errorf("%s: %w", entry.name, err)
+		}
+	}
+	return nil
+}
+
+// writeJSON returns an entry writer for an indented JSON document
+func writeJSON(value interface{}) func(io.Writer) error {
+	return func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+}
+
+// snapshotRecord is a snapshot with its full stats payload inlined
+type snapshotRecord struct {
+	models.GithubSnapshot
+	Stats json.RawMessage `json:"stats"`
+}
+
+// writeSnapshots streams every snapshot, oldest first
+func (e *Exporter) writeSnapshots(w io.Writer, userID uint) error {
+	rows, err := e.db.Model(&models.GithubSnapshot{}).Where("user_id = ?", userID).Order("created_at").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	encoder := json.NewEncoder(w)
+	for rows.Next() {
+		var snap models.GithubSnapshot
+		if err := e.db.ScanRows(rows, &snap); err != nil {
+			return err
+		}
+		record := snapshotRecord{GithubSnapshot: snap, Stats: snap.Stats}
+		if len(record.Stats) == 0 {
+			record.Stats = json.RawMessage("null")
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// writeRankHistory writes the rank history in the same format as the CSV export
+func (e *Exporter) writeRankHistory(w io.Writer, userID uint) error {
+	rows, err := e.db.Model(&models.GithubSnapshot{}).
+		Select("id, user_id, login, commits, pull_requests, issues, reviews, stars, followers, score, rank, created_at").
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	writer, err := export.NewWriter(w, export.CSV, export.RankHistoryHeader)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var snap models.GithubSnapshot
+		if err := e.db.ScanRows(rows, &snap); err != nil {
+			return err
+		}
+		if err := writer.Write(export.NewRankPoint(snap)); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// sign returns the signature of a download link
+func (e *Exporter) sign(id uint, expires int64) string {
+	mac := hmac.New(sha256.New, e.key)
+	fmt.Fprintf(mac, "data-export:%d:%d", id, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignedQuery returns the expires and signature query parameters for a
+// ready export's download link, valid until the export expires
+func (e *Exporter) SignedQuery(record *models.DataExport) string {
+	expires := record.ExpiresAt.Unix()
+	return "expires=" + strconv.FormatInt(expires, 10) + "&signature=" + e.sign(record.ID, expires)
+}
+
+// Open verifies a signed download link and opens the export's archive
+func (e *Exporter) Open(id uint, expires, signature string) (*models.DataExport, *os.File, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt ||
+		!hmac.Equal([]byte(signature), []byte(e.sign(id, expiresAt))) {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	var record models.DataExport
+	err = e.db.Where("id = ? AND status = ?", id, models.DataExportReady).First(&record).Error
+	if err != nil || record.ExpiresAt == nil || time.Now().After(*record.ExpiresAt) {
+		return nil, nil, ErrInvalidSignature
+	}
+
+	file, err := os.Open(record.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &record, file, nil
+}
+
+// Cleanup deletes the archives of expired exports and fails lost ones
+func (e *Exporter) Cleanup() error {
+	err := e.db.Model(&models.DataExport{}).
+		Where("status = ? AND created_at < ?", models.DataExportPending, time.Now().Add(-staleAfter)).
+		Updates(map[string]interface{}{"status": models.DataExportFailed, "error": "Export was interrupted"}).Error
+	if err != nil {
+		return err
+	}
+
+	var expired []models.DataExport
+	if err := e.db.Where("expires_at < ? AND path <> ''", time.Now()).Find(&expired).Error; err != nil {
+		return err
+	}
+	for _, record := range expired {
+		if err := os.Remove(record.Path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove data export %d: %v", record.ID, err)
+			continue
+		}
+		e.db.Model(&models.DataExport{}).Where("id = ?", record.ID).Update("path", "")
+	}
+	return nil
+}
+
+// Run removes expired archives periodically; it never returns
+func (e *Exporter) Run() {
+	for {
+		if err := e.Cleanup(); err != nil {
+			log.Printf("Failed to clean up data exports: %v", err)
+		}
+		time.Sleep(cleanupInterval)
+	}
+}
@@ -0,0 +1,47 @@
+package dataexport
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+)
+
+func TestLinkKey(t *testing.T) {
+	key, err := LinkKey("jwt-secret")
+	if err != nil {
+		t.Fatalf("LinkKey: %v", err)
+	}
+	again, _ := LinkKey("jwt-secret")
+	other, _ := LinkKey("other-secret")
+
+	if len(key) != 32 || !bytes.Equal(key, again) {
+		t.Errorf("key = %x, again %x", key, again)
+	}
+	if bytes.Equal(key, []byte("jwt-secret")) || bytes.Equal(key, other) {
+		t.Errorf("key isn't derived from the secret")
+	}
+}
+
+func TestOpenRejectsLinksSignedWithTheSecret(t *testing.T) {
+	key, err := LinkKey("jwt-secret")
+	if err != nil {
+		t.Fatalf("LinkKey: %v", err)
+	}
+	exporter := NewExporter(nil, t.TempDir(), key, DefaultTTL)
+	forger := NewExporter(nil, t.TempDir(), []byte("jwt-secret"), DefaultTTL)
+
+	expiresAt := time.Now().Add(time.Hour)
+	query, err := url.ParseQuery(forger.SignedQuery(&models.DataExport{ID: 1, ExpiresAt: &expiresAt}))
+	if err != nil {
+		t.Fatalf("parsing signed query: %v", err)
+	}
+
+	_, _, err = exporter.Open(1, query.Get("expires"), query.Get("signature"))
+	if !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("err = %v, want ErrInvalidSignature", err)
+	}
+}
@@ -0,0 +1,187 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+)
+
+// Format is an export file format
+type Format string
+
+// Supported export formats
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+	ICS   Format = "ics"
+)
+
+// flushEvery is how many records are buffered before flushing to the client
+const flushEvery = 100
+
+// ContentType returns the MIME type of a format
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case JSONL:
+		return "application/x-ndjson"
+	case ICS:
+		return "text/calendar; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Record is a single exported row. It is encoded as JSON for JSON Lines and
+// as its Values for CSV.
+type Record interface {
+	Values() []string
+}
+
+// Writer streams records as CSV or JSON Lines, flushing periodically so
+// large exports are never held in memory
+type Writer struct {
+	out     *bufio.Writer
+	flusher http.Flusher
+	csv     *csv.Writer
+	json    *json.Encoder
+	count   int
+}
+
+// NewWriter creates a writer for a format; CSV output starts with the header.
+// When w is an http.ResponseWriter, buffered records are sent as they are flushed.
+func NewWriter(w io.Writer, format Format, header []string) (*Writer, error) {
+	out := bufio.NewWriter(w)
+	writer := &Writer{out: out}
+	writer.flusher, _ = w.(http.Flusher)
+
+	switch format {
+	case CSV:
+		writer.csv = csv.NewWriter(out)
+		if err := writer.csv.Write(header); err != nil {
+			return nil, err
+		}
+	case JSONL:
+		writer.json = json.NewEncoder(out)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+	return writer, nil
+}
+
+// Write adds a record
+func (w *Writer) Write(record Record) error {
+	var err error
+	if w.csv != nil {
+		err = w.csv.Write(record.Values())
+	} else {
+		err = w.json.Encode(record)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.count++
+	if w.count%flushEvery == 0 {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush sends buffered records to the underlying writer
+func (w *Writer) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := w.out.Flush(); err != nil {
+		return err
+	}
+	if w.flusher != nil {
+		w.flusher.Flush()
+	}
+	return nil
+}
+
+// CalendarHeader is the CSV header for contribution calendar exports
+var CalendarHeader = []string{"date", "contributions"}
+
+// CalendarDay is an exported contribution calendar day
+type CalendarDay struct {
+	Date          string `json:"date"`
+	Contributions int    `json:"contributions"`
+}
+
+// Values returns the day as a CSV row
+func (d CalendarDay) Values() []string {
+	return []string{d.Date, strconv.Itoa(d.Contributions)}
+}
+
+// WriteCalendar streams every day of a contribution calendar
+func WriteCalendar(w *Writer, calendar github.ContributionCalendar) error {
+	for _, week := range calendar.Weeks {
+		for _, day := range week.ContributionDays {
+			if err := w.Write(CalendarDay{Date: day.Date, Contributions: day.ContributionCount}); err != nil {
+				return err
+			}
+		}
+	}
+	return w.Flush()
+}
+
+// RankHistoryHeader is the CSV header for rank history exports
+var RankHistoryHeader = []string{"recorded_at", "rank", "score", "commits", "pull_requests", "issues", "reviews", "stars", "followers"}
+
+// RankPoint is an exported snapshot of a user's rank and scored metrics
+type RankPoint struct {
+	RecordedAt   time.Time `json:"recorded_at"`
+	Rank         string    `json:"rank"`
+	Score        int       `json:"score"`
+	Commits      int       `json:"commits"`
+	PullRequests int       `json:"pull_requests"`
+	Issues       int       `json:"issues"`
+	Reviews      int       `json:"reviews"`
+	Stars        int       `json:"stars"`
+	Followers    int       `json:"followers"`
+}
+
+// NewRankPoint creates a rank history entry from a snapshot
+func NewRankPoint(snapshot models.GithubSnapshot) RankPoint {
+	return RankPoint{
+		RecordedAt:   snapshot.CreatedAt.UTC(),
+		Rank:         snapshot.Rank,
+		Score:        snapshot.Score,
+		Commits:      snapshot.Commits,
+		PullRequests: snapshot.PullRequests,
+		Issues:       snapshot.Issues,
+		Reviews:      snapshot.Reviews,
+		Stars:        snapshot.Stars,
+		Followers:    snapshot.Followers,
+	}
+}
+
+// Values returns the entry as a CSV row
+func (p RankPoint) Values() []string {
+	return []string{
+		p.RecordedAt.Format(time.RFC3339),
+		p.Rank,
+		strconv.Itoa(p.Score),
+		strconv.Itoa(p.Commits),
+		strconv.Itoa(p.PullRequests),
+		strconv.Itoa(p.Issues),
+		strconv.Itoa(p.Reviews),
+		strconv.Itoa(p.Stars),
+		strconv.Itoa(p.Followers),
+	}
+}
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+)
+
+// icsEscaper escapes iCalendar TEXT values
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// WriteCalendarICS streams an iCalendar feed with an all-day event for each
+// day with contributions. Event UIDs are stable per login and date so
+// subscribed calendars update days in place.
+func WriteCalendarICS(w io.Writer, login string, calendar github.ContributionCalendar, updatedAt time.Time) error {
+	out := bufio.NewWriter(w)
+	flusher, _ := w.(http.Flusher)
+	stamp := updatedAt.UTC().Format("20060102T150405Z")
+
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(out, format+"\r\n", args...)
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//auth-service//GitHub contributions//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:%s", icsEscaper.Replace(login+"'s GitHub contributions"))
+
+	count := 0
+	for _, week := range calendar.Weeks {
+		for _, day := range week.ContributionDays {
+			if day.ContributionCount == 0 {
+				continue
+			}
+			date, err := time.Parse("2006-01-02", day.Date)
+			if err != nil {
+				continue
+			}
+
+			summary := fmt.Sprintf("%d contributions", day.ContributionCount)
+			if day.ContributionCount == 1 {
+				summary = "1 contribution"
+			}
+
+			line("BEGIN:VEVENT")
+			line("UID:%s-%s@contributions", date.Format("20060102"), icsEscaper.Replace(strings.ToLower(login)))
+			line("DTSTAMP:%s", stamp)
+			line("DTSTART;VALUE=DATE:%s", date.Format("20060102"))
+			line("DTEND;VALUE=DATE:%s", date.AddDate(0, 0, 1).Format("20060102"))
+			line("SUMMARY:%s", summary)
+			line("TRANSP:TRANSPARENT")
+			line("END:VEVENT")
+
+			count++
+			if count%flushEvery == 0 {
+				if err := out.Flush(); err != nil {
+					return err
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+			}
+		}
+	}
+
+	line("END:VCALENDAR")
+	return out.Flush()
+}
@@ -0,0 +1,320 @@
+// Package gitea fetches developer statistics from Gitea (and Forgejo) REST
+// APIs and maps them onto the same metrics used for GitHub profiles.
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+)
+
+// pageSize is the number of items requested per page
+const pageSize = 50
+
+// maxPinned is the number of most-starred repositories returned as pinned repositories
+const maxPinned = 6
+
+// ErrUserNotFound is returned when no Gitea user has the requested username
+var ErrUserNotFound = errors.New("Gitea user not found")
+
+// ErrHostRequired is returned when no instance host is given, since Gitea has
+// no default public instance
+var ErrHostRequired = errors.New("Gitea host is required")
+
+// Client fetches profiles from Gitea instances
+type Client struct {
+	// BaseURL overrides the instance URL derived from the account host (e.g. for a local stub server)
+	BaseURL string
+	// HTTPClient is the client requests are made with; nil uses http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// NewClient creates a Gitea client
+func NewClient(httpClient *http.Client) *Client {
+	return &Client{HTTPClient: httpClient}
+}
+
+// user is a Gitea user as returned by the users API
+type user struct {
+	Login          string `json:"login"`
+	FullName       string `json:"full_name"`
+	AvatarURL      string `json:"avatar_url"`
+	Description    string `json:"description"`
+	FollowersCount int    `json:"followers_count"`
+}
+
+// heatmapEntry is the number of contributions at a point in time
+type heatmapEntry struct {
+	Timestamp     int64 `json:"timestamp"`
+	Contributions int   `json:"contributions"`
+}
+
+// activity is an entry in a user's activity feed
+type activity struct {
+	OpType    string    `json:"op_type"`
+	Content   string    `json:"content"`
+	IsPrivate bool      `json:"is_private"`
+	Created   time.Time `json:"created"`
+}
+
+// repository is a Gitea repository owned by the user
+type repository struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	HTMLURL     string `json:"html_url"`
+	Stars       int    `json:"stars_count"`
+	Forks       int    `json:"forks_count"`
+	Fork        bool   `json:"fork"`
+	Private     bool   `json:"private"`
+	Owner       struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+// FetchUserProfile fetches a Gitea user's profile and maps commits, pull
+// requests, issues, reviews and stars onto GitHub profile statistics. The
+// contribution calendar comes from the user's heatmap. Activity in private
+// repositories is only counted with opts.IncludePrivate.
+func (c *Client) FetchUserProfile(ctx context.Context, username, token string, opts github.FetchOptions) (*github.UserProfileStats, error) {
+	base, err := c.baseURL(opts.Host)
+	if err != nil {
+		return nil, err
+	}
+	period := opts.PeriodOrDefault()
+	userPath := base + "/api/v1/users/" + url.PathEscape(username)
+
+	var profile user
+	if err := c.get(ctx, userPath, token, &profile); err != nil {
+		return nil, err
+	}
+
+	stats := &github.UserProfileStats{
+		Login:     profile.Login,
+		Name:      profile.FullName,
+		AvatarURL: profile.AvatarURL,
+		Bio:       profile.Description,
+		Followers: profile.FollowersCount,
+		Period:    period,
+	}
+
+	// Build the contribution calendar from the heatmap
+	var heatmap []heatmapEntry
+	if err := c.get(ctx, userPath+"/heatmap", token, &heatmap); err != nil {
+		return nil, err
+	}
+	dayCounts := make(map[string]int)
+	for _, entry := range heatmap {
+		at := time.Unix(entry.Timestamp, 0).UTC()
+		if at.Before(period.From) || at.After(period.To) {
+			continue
+		}
+		dayCounts[at.Format("2006-01-02")] += entry.Contributions
+	}
+
+	// Map the activity feed, newest first, until it leaves the period. The
+	// heatmap counts each private action too, which the feed tells apart.
+	private := github.PrivateContributions{Days: make(map[string]int)}
+	var privateStats github.UserProfileStats
+	maxPages := opts.RepositoryPageBudget()
+feed:
+	for page := 1; page <= maxPages; page++ {
+		var activities []activity
+		feedURL := fmt.Sprintf("%s/activities/feeds?only-performed-by=true&limit=%d&page=%d", userPath, pageSize, page)
+		if err := c.get(ctx, feedURL, token, &activities); err != nil {
+			return nil, err
+		}
+
+		for _, a := range activities {
+			if a.Created.Before(period.From) {
+				break feed
+			}
+			if a.Created.After(period.To) {
+				continue
+			}
+			if a.IsPrivate {
+				private.Days[a.Created.UTC().Format("2006-01-02")]++
+				if !opts.IncludePrivate {
+					continue
+				}
+				mapActivity(a, &privateStats)
+			}
+			mapActivity(a, stats)
+		}
+
+		if len(activities) < pageSize {
+			break
+		}
+	}
+
+	if opts.IncludePrivate {
+		private.Commits = privateStats.TotalCommits
+		private.PullRequests = privateStats.TotalPullRequests
+		private.Issues = privateStats.TotalIssues
+		private.Reviews = privateStats.TotalReviews
+		stats.IncludesPrivate = true
+		stats.Private = &private
+	} else {
+		for date, count := range private.Days {
+			dayCounts[date] -= count
+			if dayCounts[date] <= 0 {
+				delete(dayCounts, date)
+			}
+		}
+	}
+	stats.ContributionCalendar = github.CalendarFromCounts(dayCounts)
+
+	// Sum stars and language sizes across the user's public repositories
+	languages := github.NewLanguageAggregator(opts.LanguagesToIgnore())
+	var repos []repository
+	for page := 1; page <= maxPages; page++ {
+		var batch []repository
+		reposURL := fmt.Sprintf("%s/repos?limit=%d&page=%d", userPath, pageSize, page)
+		if err := c.get(ctx, reposURL, token, &batch); err != nil {
+			return nil, err
+		}
+
+		for _, repo := range batch {
+			if repo.Private {
+				continue
+			}
+			repos = append(repos, repo)
+			stats.TotalStarsEarned += repo.Stars
+
+			// Forks mostly contain someone else's code
+			if repo.Fork {
+				continue
+			}
+			var sizes map[string]int
+			languagesURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/languages", base, url.PathEscape(repo.Owner.Login), url.PathEscape(repo.Name))
+			if err := c.get(ctx, languagesURL, token, &sizes); err != nil {
+				return nil, err
+			}
+			for name, size := range sizes {
+				languages.Add(name, "", size)
+			}
+			languages.FinishRepository()
+		}
+
+		if len(batch) < pageSize {
+			break
+		}
+		if page == maxPages {
+			stats.StarsTruncated = true
+		}
+	}
+
+	stats.TotalPublicRepositories = len(repos)
+	stats.PinnedRepositories = pinnedRepositories(repos)
+	stats.TopLanguages = languages.Top(github.MaxTopLanguages)
+
+	return stats, nil
+}
+
+// mapActivity adds a feed activity to the stats. Approvals, change requests
+// and pull request comments count as reviews.
+func mapActivity(a activity, stats *github.UserProfileStats) {
+	switch a.OpType {
+	case "commit_repo":
+		// Push content lists the pushed commits
+		var push struct {
+			Len int `json:"Len"`
+		}
+		if err := json.Unmarshal([]byte(a.Content), &push); err == nil && push.Len > 0 {
+			stats.TotalCommits += push.Len
+		} else {
+			stats.TotalCommits++
+		}
+	case "create_pull_request":
+		stats.TotalPullRequests++
+	case "create_issue":
+		stats.TotalIssues++
+	case "approve_pull_request", "reject_pull_request", "comment_pull":
+		stats.TotalReviews++
+	}
+}
+
+// pinnedRepositories returns the user's most starred original repositories
+func pinnedRepositories(repos []repository) []github.Repository {
+	originals := make([]repository, 0, len(repos))
+	for _, r := range repos {
+		if !r.Fork {
+			originals = append(originals, r)
+		}
+	}
+
+	sort.SliceStable(originals, func(i, j int) bool {
+		return originals[i].Stars > originals[j].Stars
+	})
+	if len(originals) > maxPinned {
+		originals = originals[:maxPinned]
+	}
+
+	pinned := make([]github.Repository, 0, len(originals))
+	for _, r := range originals {
+		pinned = append(pinned, github.Repository{
+			Name:           r.Name,
+			Description:    r.Description,
+			StargazerCount: r.Stars,
+			ForkCount:      r.Forks,
+			URL:            r.HTMLURL,
+		})
+	}
+	return pinned
+}
+
+// baseURL returns the instance URL for a host
+func (c *Client) baseURL(host string) (string, error) {
+	if c.BaseURL != "" {
+		return strings.TrimSuffix(c.BaseURL, "/"), nil
+	}
+	if host == "" {
+		return "", ErrHostRequired
+	}
+	return "https://" + host, nil
+}
+
+// get performs an authenticated GET request and decodes the JSON response
+func (c *Client) get(ctx context.Context, url, token string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrUserNotFound
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return &github.RateLimitError{ResetAt: time.Now().Add(time.Duration(retryAfter) * time.Second)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &github.UpstreamError{
+			StatusCode: resp.StatusCode,
+			Err:        errors.New("Gitea API returned status " + strconv.Itoa(resp.StatusCode)),
+		}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
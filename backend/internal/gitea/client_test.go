@@ -0,0 +1,216 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+)
+
+// testToken is the token the stub server accepts
+const testToken = "test-token"
+
+// testPeriod covers the stub server's activity
+var testPeriod = github.TimeRange{
+	From: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	To:   time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC),
+}
+
+// unix returns the Unix time of a date in January 2025
+func unix(day int) int64 {
+	return time.Date(2025, 1, day, 12, 0, 0, 0, time.UTC).Unix()
+}
+
+// newStubServer serves a Gitea user "alice"
+func newStubServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/users/alice", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, user{Login: "alice", FullName: "Alice", AvatarURL: "https://gitea.example/a.png", Description: "Hi", FollowersCount: 5})
+	})
+	mux.HandleFunc("GET /api/v1/users/alice/heatmap", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, []heatmapEntry{
+			{Timestamp: time.Date(2024, 12, 20, 12, 0, 0, 0, time.UTC).Unix(), Contributions: 9},
+			{Timestamp: unix(2), Contributions: 3},
+			{Timestamp: unix(2) + 60, Contributions: 1},
+			{Timestamp: unix(5), Contributions: 2},
+			{Timestamp: unix(7), Contributions: 1},
+			{Timestamp: unix(8), Contributions: 1},
+		})
+	})
+	mux.HandleFunc("GET /api/v1/users/alice/activities/feeds", func(w http.ResponseWriter, r *http.Request) {
+		// Newest first; the last entry is before the period
+		respondJSON(w, []activity{
+			{OpType: "create_pull_request", IsPrivate: true, Created: time.Unix(unix(8), 0)},
+			{OpType: "commit_repo", Content: `{"Len": 5}`, IsPrivate: true, Created: time.Unix(unix(7), 0)},
+			{OpType: "comment_pull", Created: time.Unix(unix(6), 0)},
+			{OpType: "approve_pull_request", Created: time.Unix(unix(5), 0)},
+			{OpType: "create_issue", Created: time.Unix(unix(4), 0)},
+			{OpType: "create_pull_request", Created: time.Unix(unix(3), 0)},
+			{OpType: "commit_repo", Content: `{"Len": 3}`, Created: time.Unix(unix(2), 0)},
+			{OpType: "commit_repo", Content: "", Created: time.Unix(unix(2), 0)},
+			{OpType: "commit_repo", Content: `{"Len": 10}`, Created: time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC)},
+		})
+	})
+	mux.HandleFunc("GET /api/v1/users/alice/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"name": "small", "html_url": "https://gitea.example/alice/small", "stars_count": 2, "owner": {"login": "alice"}},
+			{"name": "fork", "stars_count": 50, "fork": true, "owner": {"login": "alice"}},
+			{"name": "secret", "stars_count": 100, "private": true, "owner": {"login": "alice"}},
+			{"name": "big", "description": "Popular", "html_url": "https://gitea.example/alice/big", "stars_count": 10, "forks_count": 3, "owner": {"login": "alice"}}
+		]`))
+	})
+	mux.HandleFunc("GET /api/v1/repos/alice/small/languages", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, map[string]int{"Go": 100})
+	})
+	mux.HandleFunc("GET /api/v1/repos/alice/big/languages", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, map[string]int{"Go": 300, "Rust": 600})
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// respondJSON writes a JSON response
+func respondJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestFetchUserProfile(t *testing.T) {
+	server := newStubServer(t)
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+
+	stats, err := client.FetchUserProfile(context.Background(), "alice", testToken, github.FetchOptions{Period: testPeriod})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+
+	if stats.Login != "alice" || stats.Name != "Alice" || stats.Bio != "Hi" || stats.Followers != 5 {
+		t.Errorf("profile = %+v", stats)
+	}
+	// Pushes count their commits, or one when the content can't be read
+	if stats.TotalCommits != 4 || stats.TotalPullRequests != 1 || stats.TotalIssues != 1 || stats.TotalReviews != 2 {
+		t.Errorf("totals = %d commits, %d PRs, %d issues, %d reviews",
+			stats.TotalCommits, stats.TotalPullRequests, stats.TotalIssues, stats.TotalReviews)
+	}
+	// Heatmap entries outside the period are left out
+	if stats.ContributionCalendar.TotalContributions != 6 {
+		t.Errorf("calendar total = %d, want 6", stats.ContributionCalendar.TotalContributions)
+	}
+	// Private repositories are skipped
+	if stats.TotalStarsEarned != 62 || stats.TotalPublicRepositories != 3 || stats.StarsTruncated {
+		t.Errorf("stars = %d over %d repositories (truncated %v)", stats.TotalStarsEarned, stats.TotalPublicRepositories, stats.StarsTruncated)
+	}
+	if len(stats.PinnedRepositories) != 2 || stats.PinnedRepositories[0].Name != "big" || stats.PinnedRepositories[1].Name != "small" {
+		t.Errorf("pinned = %+v", stats.PinnedRepositories)
+	}
+	if len(stats.TopLanguages) != 2 || stats.TopLanguages[0].Name != "Rust" {
+		t.Errorf("languages = %+v", stats.TopLanguages)
+	}
+}
+
+func TestFetchUserProfileIncludePrivate(t *testing.T) {
+	server := newStubServer(t)
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+
+	public, err := client.FetchUserProfile(context.Background(), "alice", testToken, github.FetchOptions{Period: testPeriod})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+	if public.IncludesPrivate || public.Private != nil {
+		t.Errorf("private contributions reported without consent")
+	}
+
+	stats, err := client.FetchUserProfile(context.Background(), "alice", testToken, github.FetchOptions{Period: testPeriod, IncludePrivate: true})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+	if stats.TotalCommits != 9 || stats.TotalPullRequests != 2 || stats.ContributionCalendar.TotalContributions != 8 {
+		t.Errorf("totals = %d commits, %d PRs, calendar %d",
+			stats.TotalCommits, stats.TotalPullRequests, stats.ContributionCalendar.TotalContributions)
+	}
+	private := stats.Private
+	if !stats.IncludesPrivate || private == nil || private.Commits != 5 || private.PullRequests != 1 || len(private.Days) != 2 {
+		t.Fatalf("private = %+v", private)
+	}
+
+	// Removing the private part gives the profile fetched without consent
+	without := stats.WithoutPrivate()
+	if without.TotalCommits != public.TotalCommits || without.TotalPullRequests != public.TotalPullRequests ||
+		without.ContributionCalendar.TotalContributions != public.ContributionCalendar.TotalContributions {
+		t.Errorf("without private = %+v, want %+v", without, public)
+	}
+}
+
+func TestFetchUserProfileHostRequired(t *testing.T) {
+	_, err := NewClient(nil).FetchUserProfile(context.Background(), "alice", testToken, github.FetchOptions{Period: testPeriod})
+	if !errors.Is(err, ErrHostRequired) {
+		t.Fatalf("err = %v, want ErrHostRequired", err)
+	}
+}
+
+func TestFetchUserProfileErrorStatuses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header map[string]string
+		check  func(t *testing.T, err error)
+	}{
+		{"not found", http.StatusNotFound, nil, func(t *testing.T, err error) {
+			if !errors.Is(err, ErrUserNotFound) {
+				t.Errorf("err = %v, want ErrUserNotFound", err)
+			}
+		}},
+		{"rate limited", http.StatusTooManyRequests, map[string]string{"Retry-After": "60"}, func(t *testing.T, err error) {
+			var limitErr *github.RateLimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("err = %v, want RateLimitError", err)
+			}
+			if wait := time.Until(limitErr.ResetAt); wait < 55*time.Second || wait > 65*time.Second {
+				t.Errorf("reset in %v, want about a minute", wait)
+			}
+		}},
+		{"forbidden", http.StatusForbidden, nil, func(t *testing.T, err error) {
+			var upstreamErr *github.UpstreamError
+			if !errors.As(err, &upstreamErr) || upstreamErr.StatusCode != http.StatusForbidden {
+				t.Errorf("err = %v, want 403 UpstreamError", err)
+			}
+		}},
+		{"server error", http.StatusInternalServerError, nil, func(t *testing.T, err error) {
+			var upstreamErr *github.UpstreamError
+			if !errors.As(err, &upstreamErr) || upstreamErr.StatusCode != http.StatusInternalServerError {
+				t.Errorf("err = %v, want 500 UpstreamError", err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, value := range tt.header {
+					w.Header().Set(key, value)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+			_, err := client.FetchUserProfile(context.Background(), "alice", testToken, github.FetchOptions{Period: testPeriod})
+			tt.check(t, err)
+		})
+	}
+}
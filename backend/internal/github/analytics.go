@@ -0,0 +1,108 @@
+package github
+
+import "time"
+
+// ContributionAnalytics summarizes activity patterns in a contribution calendar
+type ContributionAnalytics struct {
+	CurrentStreak             int      `json:"current_streak"`
+	LongestStreak             int      `json:"longest_streak"`
+	MostActiveWeekday         string   `json:"most_active_weekday"`
+	AverageDailyContributions float64  `json:"average_daily_contributions"`
+	BusiestMonth              string   `json:"busiest_month"`
+	BusiestMonthContributions int      `json:"busiest_month_contributions"`
+	ThisWeekContributions     int      `json:"this_week_contributions"`
+	LastWeekContributions     int      `json:"last_week_contributions"`
+	WeekOverWeekChangePercent *float64 `json:"week_over_week_change_percent"` // Null when last week had no contributions
+}
+
+// AnalyzeContributions computes streaks, averages and trends from a
+// contribution calendar. Days are expected in chronological order, as
+// returned by the GitHub API.
+func AnalyzeContributions(calendar ContributionCalendar) ContributionAnalytics {
+	var days []ContributionDay
+	for _, week := range calendar.Weeks {
+		days = append(days, week.ContributionDays...)
+	}
+
+	var analytics ContributionAnalytics
+	if len(days) == 0 {
+		return analytics
+	}
+
+	// Longest streak and weekday/month totals
+	var weekdayTotals [7]int
+	monthTotals := make(map[string]int)
+	var months []string
+	total, streak := 0, 0
+
+	for _, day := range days {
+		total += day.ContributionCount
+
+		if day.ContributionCount > 0 {
+			streak++
+			if streak > analytics.LongestStreak {
+				analytics.LongestStreak = streak
+			}
+		} else {
+			streak = 0
+		}
+
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		weekdayTotals[date.Weekday()] += day.ContributionCount
+
+		month := date.Format("2006-01")
+		if _, seen := monthTotals[month]; !seen {
+			months = append(months, month)
+		}
+		monthTotals[month] += day.ContributionCount
+	}
+
+	// Current streak counts back from the last day; a quiet last day doesn't
+	// break the streak since it may still be in progress
+	last := len(days) - 1
+	if days[last].ContributionCount == 0 {
+		last--
+	}
+	for i := last; i >= 0 && days[i].ContributionCount > 0; i-- {
+		analytics.CurrentStreak++
+	}
+
+	// Most active weekday
+	busiestWeekday := -1
+	for weekday, count := range weekdayTotals {
+		if count > 0 && (busiestWeekday < 0 || count > weekdayTotals[busiestWeekday]) {
+			busiestWeekday = weekday
+		}
+	}
+	if busiestWeekday >= 0 {
+		analytics.MostActiveWeekday = time.Weekday(busiestWeekday).String()
+	}
+
+	// Busiest month, earliest month wins ties
+	for _, month := range months {
+		if monthTotals[month] > analytics.BusiestMonthContributions {
+			analytics.BusiestMonth = month
+			analytics.BusiestMonthContributions = monthTotals[month]
+		}
+	}
+
+	analytics.AverageDailyContributions = roundOneDecimal(float64(total) / float64(len(days)))
+
+	// Week-over-week trend compares the last 7 days with the 7 days before
+	for i := len(days) - 1; i >= 0 && i >= len(days)-14; i-- {
+		if i >= len(days)-7 {
+			analytics.ThisWeekContributions += days[i].ContributionCount
+		} else {
+			analytics.LastWeekContributions += days[i].ContributionCount
+		}
+	}
+	if analytics.LastWeekContributions > 0 {
+		change := roundOneDecimal(float64(analytics.ThisWeekContributions-analytics.LastWeekContributions) * 100 / float64(analytics.LastWeekContributions))
+		analytics.WeekOverWeekChangePercent = &change
+	}
+
+	return analytics
+}
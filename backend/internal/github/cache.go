@@ -0,0 +1,79 @@
+package github
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProfileCacheTTL is how long looked-up profiles are reused
+const DefaultProfileCacheTTL = 15 * time.Minute
+
+// maxCachedProfiles bounds the cache's memory use
+const maxCachedProfiles = 1000
+
+// cachedProfile is a profile along with when it was fetched
+type cachedProfile struct {
+	stats     *UserProfileStats
+	fetchedAt time.Time
+}
+
+// ProfileCache keeps recently fetched profiles per host, login and period so
+// repeated lookups don't spend rate limit
+type ProfileCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	profiles map[string]cachedProfile
+}
+
+// NewProfileCache creates a cache whose entries expire after ttl
+func NewProfileCache(ttl time.Duration) *ProfileCache {
+	return &ProfileCache{
+		ttl:      ttl,
+		profiles: make(map[string]cachedProfile),
+	}
+}
+
+// profileCacheKey identifies a profile; logins are case-insensitive. Periods
+// are keyed by day, since most end at the time of the request and would
+// otherwise never match; entries expire long before the day's stats change much.
+func profileCacheKey(host, login string, period TimeRange) string {
+	return strings.ToLower(host) + "/" + strings.ToLower(login) + "/" +
+		period.From.UTC().Format("2006-01-02") + "/" + period.To.UTC().Format("2006-01-02")
+}
+
+// Get returns a cached profile and when it was fetched
+func (c *ProfileCache) Get(host, login string, period TimeRange) (*UserProfileStats, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.profiles[profileCacheKey(host, login, period)]
+	if !ok || time.Since(entry.fetchedAt) > c.ttl {
+		return nil, time.Time{}, false
+	}
+	return entry.stats, entry.fetchedAt, true
+}
+
+// Set caches a profile fetched now
+func (c *ProfileCache) Set(host, login string, period TimeRange, stats *UserProfileStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Drop expired entries, then the oldest, when full
+	if len(c.profiles) >= maxCachedProfiles {
+		oldestKey, oldest := "", time.Now()
+		for key, entry := range c.profiles {
+			if time.Since(entry.fetchedAt) > c.ttl {
+				delete(c.profiles, key)
+			} else if entry.fetchedAt.Before(oldest) {
+				oldestKey, oldest = key, entry.fetchedAt
+			}
+		}
+		if len(c.profiles) >= maxCachedProfiles {
+			delete(c.profiles, oldestKey)
+		}
+	}
+
+	c.profiles[profileCacheKey(host, login, period)] = cachedProfile{stats: stats, fetchedAt: time.Now()}
+}
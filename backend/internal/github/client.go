@@ -2,6 +2,7 @@ package github
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/shurcooL/githubv4"
@@ -19,10 +20,23 @@ type UserProfileStats struct {
 	TotalIssues             int                  `json:"total_issues"`
 	TotalReviews            int                  `json:"total_reviews"`
 	TotalStarsEarned        int                  `json:"total_stars_earned"`
+	ContributedStars        int                  `json:"contributed_stars"` // Included in TotalStarsEarned
+	StarsTruncated          bool                 `json:"stars_truncated"`   // Repository page budget ran out
+	TopLanguages            []LanguageStat       `json:"top_languages"`
 	Followers               int                  `json:"followers"`
 	ContributionCalendar    ContributionCalendar `json:"contribution_calendar"`
 	PinnedRepositories      []Repository         `json:"pinned_repositories"`
 	TotalPublicRepositories int                  `json:"total_public_repositories"`
+	Period                  TimeRange            `json:"period"`
+	RateLimit               *RateLimit           `json:"rate_limit,omitempty"`
+	// IncludesPrivate is set when the totals and calendar count private
+	// contributions, which are broken down in Private
+	IncludesPrivate bool                  `json:"includes_private"`
+	Private         *PrivateContributions `json:"private,omitempty"`
+	// CalendarIncludesPrivate is set when private contributions were left
+	// out of the totals but the calendar may still count some of them,
+	// since GitHub didn't report the days of all of them
+	CalendarIncludesPrivate bool `json:"calendar_includes_private,omitempty"`
 }
 
 // ContributionCalendar represents the contribution calendar data
@@ -45,12 +59,21 @@ type ContributionDay struct {
 
 // Repository represents a GitHub repository
 type Repository struct {
-	Name            string   `json:"name"`
-	Description     string   `json:"description"`
-	StargazerCount  int      `json:"stargazer_count"`
-	ForkCount       int      `json:"fork_count"`
-	PrimaryLanguage Language `json:"primary_language"`
-	URL             string   `json:"url"`
+	Name             string     `json:"name"`
+	Description      string     `json:"description"`
+	StargazerCount   int        `json:"stargazer_count"`
+	ForkCount        int        `json:"fork_count"`
+	PrimaryLanguage  Language   `json:"primary_language"`
+	URL              string     `json:"url"`
+	Topics           []string   `json:"topics"`
+	License          string     `json:"license,omitempty"` // SPDX ID, or the license name when it has none
+	PushedAt         *time.Time `json:"pushed_at"`
+	OpenIssues       int        `json:"open_issues"`
+	OpenPullRequests int        `json:"open_pull_requests"`
+	Releases         int        `json:"releases"`
+	RecentCommits    int        `json:"recent_commits"` // Commits to the default branch in the last RecentActivityDays days
+	IsFork           bool       `json:"is_fork"`
+	IsArchived       bool       `json:"is_archived"`
 }
 
 // Language represents a programming language
@@ -59,9 +82,44 @@ type Language struct {
 	Color string `json:"color"`
 }
 
+// contributionsCollection is the GraphQL structure for a user's contributions
+// within a time range of at most one year
+type contributionsCollection struct {
+	TotalCommitContributions            githubv4.Int
+	TotalPullRequestContributions       githubv4.Int
+	TotalIssueContributions             githubv4.Int
+	TotalPullRequestReviewContributions githubv4.Int
+	RestrictedContributionsCount        githubv4.Int
+	// Contribution counts by repository, used to tell private contributions apart
+	CommitContributionsByRepository            []repositoryContributionTotals `graphql:"commitContributionsByRepository(maxRepositories: 100)"`
+	PullRequestContributionsByRepository       []repositoryContributionTotals `graphql:"pullRequestContributionsByRepository(maxRepositories: 100)"`
+	IssueContributionsByRepository             []repositoryContributionTotals `graphql:"issueContributionsByRepository(maxRepositories: 100)"`
+	PullRequestReviewContributionsByRepository []repositoryContributionTotals `graphql:"pullRequestReviewContributionsByRepository(maxRepositories: 100)"`
+	ContributionCalendar                       struct {
+		TotalContributions githubv4.Int
+		Weeks              []struct {
+			ContributionDays []struct {
+				Color             githubv4.String
+				ContributionCount githubv4.Int
+				Date              githubv4.String
+			}
+		}
+	}
+}
+
+// ContributionsQuery fetches only contributions, used for the additional
+// yearly chunks of a multi-year time range
+type ContributionsQuery struct {
+	RateLimit rateLimitInfo
+	User      struct {
+		ContributionsCollection contributionsCollection `graphql:"contributionsCollection(from: $from, to: $to)"`
+	} `graphql:"user(login: $username)"`
+}
+
 // UserProfileQuery is the GraphQL query structure for GitHub API
 type UserProfileQuery struct {
-	User struct {
+	RateLimit rateLimitInfo
+	User      struct {
 		Login     githubv4.String
 		Name      githubv4.String
 		AvatarURL githubv4.String `graphql:"avatarUrl"`
@@ -69,50 +127,55 @@ type UserProfileQuery struct {
 		Followers struct {
 			TotalCount githubv4.Int
 		}
-		ContributionsCollection struct {
-			TotalCommitContributions            githubv4.Int
-			TotalPullRequestContributions       githubv4.Int
-			TotalIssueContributions             githubv4.Int
-			TotalPullRequestReviewContributions githubv4.Int
-			ContributionCalendar                struct {
-				TotalContributions githubv4.Int
-				Weeks              []struct {
-					ContributionDays []struct {
-						Color             githubv4.String
-						ContributionCount githubv4.Int
-						Date              githubv4.String
-					}
-				}
-			}
-		} `graphql:"contributionsCollection(from: $from, to: $to)"`
-		PinnedItems struct {
+		ContributionsCollection contributionsCollection `graphql:"contributionsCollection(from: $from, to: $to)"`
+		PinnedItems             struct {
 			Nodes []struct {
-				Repository struct {
-					Name            githubv4.String
-					Description     githubv4.String
-					StargazerCount  githubv4.Int `graphql:"stargazerCount"`
-					ForkCount       githubv4.Int
-					PrimaryLanguage struct {
-						Name  githubv4.String
-						Color githubv4.String
-					}
-					URL githubv4.String `graphql:"url"`
-				} `graphql:"... on Repository"`
+				Repository repositoryDetails `graphql:"... on Repository"`
 			}
 		} `graphql:"pinnedItems(first: 6, types: REPOSITORY)"`
-		Repositories struct {
-			TotalCount githubv4.Int
-			Nodes      []struct {
-				StargazerCount githubv4.Int `graphql:"stargazerCount"`
-			}
-		} `graphql:"repositories(first: 100, orderBy: {field: STARGAZERS, direction: DESC}, ownerAffiliations: OWNER, privacy: PUBLIC)"`
+		Repositories ownedRepositoryPage `graphql:"repositories(first: 100, after: $cursor, orderBy: {field: STARGAZERS, direction: DESC}, ownerAffiliations: OWNER, privacy: PUBLIC)"`
 	} `graphql:"user(login: $username)"`
 }
 
-// FetchUserProfile fetches a GitHub user's profile and contribution statistics
-// using the GitHub GraphQL API v4
-func FetchUserProfile(username, token string) (*UserProfileStats, error) {
-	ctx := context.Background()
+// DefaultEndpoint is the public GitHub GraphQL API endpoint
+const DefaultEndpoint = "https://api.github.com/graphql"
+
+// ProfileFetcher fetches a GitHub user's profile and contribution statistics
+type ProfileFetcher interface {
+	FetchUserProfile(ctx context.Context, username, token string, opts FetchOptions) (*UserProfileStats, error)
+}
+
+// Client fetches profiles from a GitHub GraphQL API endpoint
+type Client struct {
+	// Endpoint is the GraphQL API URL; empty uses DefaultEndpoint
+	Endpoint string
+	// HTTPClient is the base client requests are made with; nil uses http.DefaultClient
+	HTTPClient *http.Client
+
+	budgets budgetTracker
+}
+
+// NewClient creates a client for a GraphQL endpoint
+func NewClient(endpoint string, httpClient *http.Client) *Client {
+	return &Client{Endpoint: endpoint, HTTPClient: httpClient}
+}
+
+// newSession creates a GraphQL session for an endpoint authenticated with the given token
+func (c *Client) newSession(ctx context.Context, endpoint, token string) *session {
+	// Use the configured HTTP client as the base, capturing response headers
+	base := c.HTTPClient
+	if base == nil {
+		base = http.DefaultClient
+	}
+	transport := base.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	info := &responseInfo{}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{
+		Transport: &capturingTransport{base: transport, info: info},
+		Timeout:   base.Timeout,
+	})
 
 	// Create OAuth2 token source
 	src := oauth2.StaticTokenSource(
@@ -120,68 +183,169 @@ func FetchUserProfile(username, token string) (*UserProfileStats, error) {
 	)
 	httpClient := oauth2.NewClient(ctx, src)
 
-	// Create GitHub GraphQL client
-	client := githubv4.NewClient(httpClient)
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	return &session{
+		client:  githubv4.NewEnterpriseClient(endpoint, httpClient),
+		info:    info,
+		budgets: &c.budgets,
+		key:     budgetKey(endpoint, token),
+	}
+}
+
+// FetchOptions controls what FetchUserProfile collects
+type FetchOptions struct {
+	// Host is the GitHub instance to query; empty or github.com uses the client's endpoint
+	Host string
+	// Period is the time range for contribution statistics; zero uses DefaultTimeRange
+	Period TimeRange
+	// IncludeContributedRepos adds stars from organization repositories the
+	// user has committed to or opened pull requests against
+	IncludeContributedRepos bool
+	// MaxRepositoryPages bounds repository pagination; zero uses DefaultMaxRepositoryPages
+	MaxRepositoryPages int
+	// IgnoredLanguages are left out of the language breakdown; nil uses the
+	// defaults set with SetIgnoredLanguages
+	IgnoredLanguages []string
+	// IncludePrivate counts contributions to private repositories and
+	// restricted contributions; without it they are left out of the totals
+	// and calendar. Only set it with the user's consent.
+	IncludePrivate bool
+}
 
-	// Calculate date range (start of current year to now)
-	now := time.Now()
-	startOfYear := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+// PeriodOrDefault returns the period to collect, defaulting to the current year
+func (o FetchOptions) PeriodOrDefault() TimeRange {
+	if o.Period.From.IsZero() {
+		return DefaultTimeRange(time.Now())
+	}
+	return o.Period
+}
+
+// LanguagesToIgnore returns the languages to leave out of the breakdown
+func (o FetchOptions) LanguagesToIgnore() []string {
+	if o.IgnoredLanguages == nil {
+		return defaultIgnoredLanguages
+	}
+	return o.IgnoredLanguages
+}
 
-	// Define query variables
+// RepositoryPageBudget returns the maximum number of repository pages to fetch
+func (o FetchOptions) RepositoryPageBudget() int {
+	if o.MaxRepositoryPages <= 0 {
+		return DefaultMaxRepositoryPages
+	}
+	return o.MaxRepositoryPages
+}
+
+// FetchUserProfile fetches a GitHub user's profile and contribution
+// statistics using the GitHub GraphQL API v4. Periods longer than a year are
+// queried in yearly chunks, as required by the GitHub API, and repositories
+// are paginated to count stars.
+func (c *Client) FetchUserProfile(ctx context.Context, username, token string, opts FetchOptions) (*UserProfileStats, error) {
+	period := opts.PeriodOrDefault()
+
+	// Route enterprise hosts to their own endpoint
+	endpoint := c.Endpoint
+	if opts.Host != "" && opts.Host != DefaultHost {
+		endpoint = EndpointForHost(opts.Host)
+	}
+
+	// Create GitHub GraphQL session
+	client := c.newSession(ctx, endpoint, token)
+
+	chunks := period.Chunks()
+
+	// Define query variables for the first chunk
 	variables := map[string]interface{}{
 		"username": githubv4.String(username),
-		"from":     githubv4.DateTime{Time: startOfYear},
-		"to":       githubv4.DateTime{Time: now},
+		"from":     githubv4.DateTime{Time: chunks[0].From},
+		"to":       githubv4.DateTime{Time: chunks[0].To},
+		"cursor":   (*githubv4.String)(nil),
+		"since":    recentActivitySince(),
 	}
 
 	// Execute query
 	var query UserProfileQuery
-	err := client.Query(ctx, &query, variables)
+	err := client.query(ctx, &query, variables, func() rateLimitInfo { return query.RateLimit })
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate total stars earned
-	totalStarsEarned := 0
-	for _, repo := range query.User.Repositories.Nodes {
-		totalStarsEarned += int(repo.StargazerCount)
+	// Fetch contributions for the remaining chunks
+	collections := []contributionsCollection{query.User.ContributionsCollection}
+	for _, chunk := range chunks[1:] {
+		var chunkQuery ContributionsQuery
+		err := client.query(ctx, &chunkQuery, map[string]interface{}{
+			"username": githubv4.String(username),
+			"from":     githubv4.DateTime{Time: chunk.From},
+			"to":       githubv4.DateTime{Time: chunk.To},
+		}, func() rateLimitInfo { return chunkQuery.RateLimit })
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, chunkQuery.User.ContributionsCollection)
 	}
 
-	// Build contribution calendar
+	// Calculate total stars earned across all owned repositories
+	maxPages := opts.RepositoryPageBudget()
+	owned, err := collectOwnedRepositories(ctx, client, username, query.User.Repositories, maxPages, opts.LanguagesToIgnore())
+	if err != nil {
+		return nil, err
+	}
+	totalStarsEarned, truncated := owned.stars, owned.truncated
+
+	// Optionally add stars from organization repositories the user contributes to
+	contributedStars := 0
+	if opts.IncludeContributedRepos {
+		var contributedTruncated bool
+		contributedStars, contributedTruncated, err = countContributedStars(ctx, client, username, maxPages)
+		if err != nil {
+			return nil, err
+		}
+		truncated = truncated || contributedTruncated
+	}
+
+	// Combine contributions across chunks in chronological order
 	contributionCalendar := ContributionCalendar{
-		TotalContributions: int(query.User.ContributionsCollection.ContributionCalendar.TotalContributions),
-		Weeks:              make([]ContributionWeek, 0),
+		Weeks: make([]ContributionWeek, 0),
 	}
+	var commits, pullRequests, issues, reviews int
+	private := PrivateContributions{Days: make(map[string]int)}
 
-	for _, week := range query.User.ContributionsCollection.ContributionCalendar.Weeks {
-		contributionWeek := ContributionWeek{
-			ContributionDays: make([]ContributionDay, 0),
+	breakdownBudget := maxBreakdownQueries
+	for i, collection := range collections {
+		// Private contributions are only broken down by day when there are
+		// any, since the breakdown takes at least one more query
+		chunkPrivate, complete := privateTotals(collection)
+		if chunkPrivate.any() || !complete {
+			chunkPrivate, err = fetchPrivateBreakdown(ctx, client, username, chunks[i], &breakdownBudget)
+			if err != nil {
+				return nil, err
+			}
 		}
-		for _, day := range week.ContributionDays {
-			contributionWeek.ContributionDays = append(contributionWeek.ContributionDays, ContributionDay{
-				Color:             string(day.Color),
-				ContributionCount: int(day.ContributionCount),
-				Date:              string(day.Date),
-			})
+		// GitHub doesn't report the days of restricted contributions
+		chunkPrivate.Restricted = int(collection.RestrictedContributionsCount)
+		if chunkPrivate.Restricted > 0 {
+			chunkPrivate.DaysIncomplete = true
 		}
-		contributionCalendar.Weeks = append(contributionCalendar.Weeks, contributionWeek)
+		private.add(&chunkPrivate)
+
+		commits += int(collection.TotalCommitContributions)
+		pullRequests += int(collection.TotalPullRequestContributions)
+		issues += int(collection.TotalIssueContributions)
+		reviews += int(collection.TotalPullRequestReviewContributions)
+
+		calendar := buildContributionCalendar(collection)
+		contributionCalendar.TotalContributions += calendar.TotalContributions
+		contributionCalendar.Weeks = append(contributionCalendar.Weeks, calendar.Weeks...)
 	}
 
 	// Build pinned repositories
 	pinnedRepos := make([]Repository, 0)
 	for _, item := range query.User.PinnedItems.Nodes {
-		repo := Repository{
-			Name:           string(item.Repository.Name),
-			Description:    string(item.Repository.Description),
-			StargazerCount: int(item.Repository.StargazerCount),
-			ForkCount:      int(item.Repository.ForkCount),
-			URL:            string(item.Repository.URL),
-			PrimaryLanguage: Language{
-				Name:  string(item.Repository.PrimaryLanguage.Name),
-				Color: string(item.Repository.PrimaryLanguage.Color),
-			},
-		}
-		pinnedRepos = append(pinnedRepos, repo)
+		pinnedRepos = append(pinnedRepos, item.Repository.toRepository())
 	}
 
 	// Build final stats
@@ -190,16 +354,56 @@ func FetchUserProfile(username, token string) (*UserProfileStats, error) {
 		Name:                    string(query.User.Name),
 		AvatarURL:               string(query.User.AvatarURL),
 		Bio:                     string(query.User.Bio),
-		TotalCommits:            int(query.User.ContributionsCollection.TotalCommitContributions),
-		TotalPullRequests:       int(query.User.ContributionsCollection.TotalPullRequestContributions),
-		TotalIssues:             int(query.User.ContributionsCollection.TotalIssueContributions),
-		TotalReviews:            int(query.User.ContributionsCollection.TotalPullRequestReviewContributions),
-		TotalStarsEarned:        totalStarsEarned,
+		TotalCommits:            commits,
+		TotalPullRequests:       pullRequests,
+		TotalIssues:             issues,
+		TotalReviews:            reviews,
+		TotalStarsEarned:        totalStarsEarned + contributedStars,
+		ContributedStars:        contributedStars,
+		StarsTruncated:          truncated,
+		TopLanguages:            owned.languages.Top(MaxTopLanguages),
 		Followers:               int(query.User.Followers.TotalCount),
 		ContributionCalendar:    contributionCalendar,
 		PinnedRepositories:      pinnedRepos,
 		TotalPublicRepositories: int(query.User.Repositories.TotalCount),
+		Period:                  period,
+		RateLimit:               client.budgets.get(client.key),
+		IncludesPrivate:         true,
+		Private:                 &private,
+	}
+
+	// Restricted contributions aren't part of the totals GitHub returns, so
+	// they are only added with consent; otherwise private ones are removed
+	if !opts.IncludePrivate {
+		private.Restricted = 0
+		*stats = stats.WithoutPrivate()
+	} else {
+		stats.TotalCommits += private.Restricted
 	}
 
 	return stats, nil
 }
+
+// buildContributionCalendar converts a GraphQL contribution calendar
+func buildContributionCalendar(collection contributionsCollection) ContributionCalendar {
+	contributionCalendar := ContributionCalendar{
+		TotalContributions: int(collection.ContributionCalendar.TotalContributions),
+		Weeks:              make([]ContributionWeek, 0),
+	}
+
+	for _, week := range collection.ContributionCalendar.Weeks {
+		contributionWeek := ContributionWeek{
+			ContributionDays: make([]ContributionDay, 0),
+		}
+		for _, day := range week.ContributionDays {
+			contributionWeek.ContributionDays = append(contributionWeek.ContributionDays, ContributionDay{
+				Color:             string(day.Color),
+				ContributionCount: int(day.ContributionCount),
+				Date:              string(day.Date),
+			})
+		}
+		contributionCalendar.Weeks = append(contributionCalendar.Weeks, contributionWeek)
+	}
+
+	return contributionCalendar
+}
@@ -0,0 +1,306 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github/githubtest"
+)
+
+// fixturePeriod covers every day in the octocat fixture
+var fixturePeriod = TimeRange{
+	From: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+	To:   time.Date(2025, 1, 6, 23, 59, 59, 0, time.UTC),
+}
+
+// newFixtureServer serves the octocat fixture
+func newFixtureServer(t *testing.T) (*githubtest.Server, *githubtest.User) {
+	t.Helper()
+	user, err := githubtest.Fixture("octocat")
+	if err != nil {
+		t.Fatalf("loading fixture: %v", err)
+	}
+	server := githubtest.NewServer(user)
+	t.Cleanup(server.Close)
+	return server, user
+}
+
+func TestFetchUserProfile(t *testing.T) {
+	server, user := newFixtureServer(t)
+	client := NewClient(server.URL, server.Client())
+
+	stats, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+
+	if stats.Login != "octocat" || stats.Name != "The Octocat" || stats.Followers != 42 {
+		t.Errorf("profile = %q %q %d followers", stats.Login, stats.Name, stats.Followers)
+	}
+	// The private day's five commits are left out without consent
+	if stats.TotalCommits != 9 || stats.TotalPullRequests != 3 || stats.TotalIssues != 1 || stats.TotalReviews != 4 {
+		t.Errorf("totals = %d commits, %d PRs, %d issues, %d reviews",
+			stats.TotalCommits, stats.TotalPullRequests, stats.TotalIssues, stats.TotalReviews)
+	}
+	if stats.ContributionCalendar.TotalContributions != 17 {
+		t.Errorf("calendar total = %d, want 17", stats.ContributionCalendar.TotalContributions)
+	}
+	if stats.TotalStarsEarned != 138 || stats.TotalPublicRepositories != 4 {
+		t.Errorf("stars = %d over %d repositories", stats.TotalStarsEarned, stats.TotalPublicRepositories)
+	}
+	if len(stats.PinnedRepositories) != 1 || stats.PinnedRepositories[0].Name != "hello-world" {
+		t.Errorf("pinned = %+v", stats.PinnedRepositories)
+	}
+	if stats.IncludesPrivate || stats.Private != nil {
+		t.Errorf("private contributions reported without consent")
+	}
+	if stats.RateLimit == nil || stats.RateLimit.Limit != 5000 {
+		t.Errorf("rate limit = %+v", stats.RateLimit)
+	}
+}
+
+func TestFetchUserProfilePaginatesRepositories(t *testing.T) {
+	server, user := newFixtureServer(t)
+	client := NewClient(server.URL, server.Client())
+
+	all, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+
+	server.PageSize = 1
+	before := server.Requests()
+	paged, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+
+	if paged.TotalStarsEarned != all.TotalStarsEarned || paged.StarsTruncated {
+		t.Errorf("paged stars = %d (truncated %v), want %d", paged.TotalStarsEarned, paged.StarsTruncated, all.TotalStarsEarned)
+	}
+	// The first page comes with the profile, then one query per remaining
+	// page and one for the private contributions breakdown
+	if got, want := server.Requests()-before, len(user.Repositories)+1; got != want {
+		t.Errorf("requests = %d, want %d", got, want)
+	}
+
+	limited, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod, MaxRepositoryPages: 2})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+	if !limited.StarsTruncated || limited.TotalStarsEarned >= all.TotalStarsEarned {
+		t.Errorf("limited stars = %d (truncated %v)", limited.TotalStarsEarned, limited.StarsTruncated)
+	}
+}
+
+func TestFetchUserProfileSkipsBreakdownWithoutPrivateContributions(t *testing.T) {
+	server, user := newFixtureServer(t)
+	for i := range user.Days {
+		user.Days[i].Private = false
+	}
+	client := NewClient(server.URL, server.Client())
+
+	for _, includePrivate := range []bool{false, true} {
+		before := server.Requests()
+		stats, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod, IncludePrivate: includePrivate})
+		if err != nil {
+			t.Fatalf("FetchUserProfile: %v", err)
+		}
+		if stats.TotalCommits != 14+user.Restricted*boolInt(includePrivate) {
+			t.Errorf("commits = %d", stats.TotalCommits)
+		}
+		if got := server.Requests() - before; got != 1 {
+			t.Errorf("include private %v: requests = %d, want 1", includePrivate, got)
+		}
+	}
+}
+
+func TestFetchUserProfileSplitsPrivateBreakdown(t *testing.T) {
+	server, user := newFixtureServer(t)
+	// Private reviews on two days, more than fit in one page of one
+	user.Days[2] = githubtest.Day{Date: "2025-01-01", Reviews: 1, Private: true}
+	user.Days[6].Reviews = 1
+	server.NodeLimit = 1
+	client := NewClient(server.URL, server.Client())
+
+	before := server.Requests()
+	stats, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod, IncludePrivate: true})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+
+	private := stats.Private
+	if private == nil || private.Commits != 5 || private.Reviews != 2 || private.Restricted != 3 {
+		t.Fatalf("private = %+v", private)
+	}
+	if private.Days["2025-01-01"] != 1 || private.Days["2025-01-05"] != 6 || len(private.Days) != 2 {
+		t.Errorf("private days = %v", private.Days)
+	}
+	// The profile, the whole period, then each half
+	if got := server.Requests() - before; got != 4 {
+		t.Errorf("requests = %d, want 4", got)
+	}
+
+	// Without consent both days are removed from the calendar
+	public, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+	if public.TotalReviews != 4 || public.ContributionCalendar.TotalContributions != 17 {
+		t.Errorf("public = %d reviews, calendar total %d", public.TotalReviews, public.ContributionCalendar.TotalContributions)
+	}
+}
+
+func TestFetchUserProfileRestrictedContributions(t *testing.T) {
+	server, user := newFixtureServer(t)
+	client := NewClient(server.URL, server.Client())
+
+	stats, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod, IncludePrivate: true})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+	// Restricted contributions count as commits but have no days
+	if private := stats.Private; private == nil || private.Restricted != 3 || !private.DaysIncomplete || stats.TotalCommits != 17 {
+		t.Fatalf("private = %+v, %d commits", stats.Private, stats.TotalCommits)
+	}
+	without := stats.WithoutPrivate()
+	if without.TotalCommits != 9 || !without.CalendarIncludesPrivate {
+		t.Errorf("without private = %d commits, calendar includes private %v", without.TotalCommits, without.CalendarIncludesPrivate)
+	}
+
+	public, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+	if !public.CalendarIncludesPrivate {
+		t.Errorf("public calendar isn't marked as including restricted contributions")
+	}
+
+	// Every private day is known without restricted contributions
+	user.Restricted = 0
+	public, err = client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+	if public.CalendarIncludesPrivate || public.ContributionCalendar.TotalContributions != 17 {
+		t.Errorf("public calendar total %d, includes private %v", public.ContributionCalendar.TotalContributions, public.CalendarIncludesPrivate)
+	}
+}
+
+func TestFetchUserProfileIncompleteBreakdown(t *testing.T) {
+	server, user := newFixtureServer(t)
+	user.Restricted = 0
+	// Two private reviews on one day can't be split into ranges of one each
+	user.Days[6].Reviews = 2
+	server.NodeLimit = 1
+	client := NewClient(server.URL, server.Client())
+
+	stats, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod, IncludePrivate: true})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+	if private := stats.Private; private == nil || private.Reviews != 2 || !private.DaysIncomplete {
+		t.Fatalf("private = %+v", stats.Private)
+	}
+
+	public, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+	// The review missing from the breakdown stays in the calendar
+	if public.TotalReviews != 4 || public.ContributionCalendar.TotalContributions != 18 || !public.CalendarIncludesPrivate {
+		t.Errorf("public = %d reviews, calendar total %d, includes private %v",
+			public.TotalReviews, public.ContributionCalendar.TotalContributions, public.CalendarIncludesPrivate)
+	}
+}
+
+// boolInt returns 1 for true and 0 for false
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestFetchUserProfileRateLimited(t *testing.T) {
+	server, user := newFixtureServer(t)
+	// Without private contributions the profile takes a single query
+	for i := range user.Days {
+		user.Days[i].Private = false
+	}
+	server.Budget = 1
+	client := NewClient(server.URL, server.Client())
+
+	if _, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod}); err != nil {
+		t.Fatalf("first FetchUserProfile: %v", err)
+	}
+
+	_, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod})
+	var limitErr *RateLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("err = %v, want RateLimitError", err)
+	}
+	if limitErr.Secondary || limitErr.ResetAt.Year() != 2030 {
+		t.Errorf("rate limit error = %+v", limitErr)
+	}
+
+	// The exhausted budget is remembered, so the next fetch doesn't query at all
+	requests := server.Requests()
+	if _, err := client.FetchUserProfile(context.Background(), user.Login, user.Token, FetchOptions{Period: fixturePeriod}); !errors.As(err, &limitErr) {
+		t.Fatalf("err = %v, want RateLimitError", err)
+	}
+	if server.Requests() != requests {
+		t.Errorf("queried with an exhausted budget")
+	}
+}
+
+func TestFetchUserProfileGivesUpBeforeDeadline(t *testing.T) {
+	server, user := newFixtureServer(t)
+	server.RetryAfter = 5 * time.Second
+	client := NewClient(server.URL, server.Client())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.FetchUserProfile(ctx, user.Login, user.Token, FetchOptions{Period: fixturePeriod})
+	var limitErr *RateLimitError
+	if !errors.As(err, &limitErr) || !limitErr.Secondary {
+		t.Fatalf("err = %v, want secondary RateLimitError", err)
+	}
+	// Waiting out Retry-After would pass the deadline, so there is no retry
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %v", elapsed)
+	}
+	if server.Requests() != 1 {
+		t.Errorf("requests = %d, want 1", server.Requests())
+	}
+}
+
+func TestFetchUserProfileBadCredentials(t *testing.T) {
+	server, user := newFixtureServer(t)
+	client := NewClient(server.URL, server.Client())
+
+	_, err := client.FetchUserProfile(context.Background(), user.Login, "wrong-token", FetchOptions{Period: fixturePeriod})
+	var upstreamErr *UpstreamError
+	if !errors.As(err, &upstreamErr) || upstreamErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("err = %v, want 401 UpstreamError", err)
+	}
+	// Client errors aren't retried
+	if server.Requests() != 1 {
+		t.Errorf("requests = %d, want 1", server.Requests())
+	}
+}
+
+func TestFetchUserProfileUnknownUser(t *testing.T) {
+	server, _ := newFixtureServer(t)
+	client := NewClient(server.URL, server.Client())
+
+	_, err := client.FetchUserProfile(context.Background(), "nobody", "", FetchOptions{Period: fixturePeriod})
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("err = %v, want ErrUserNotFound", err)
+	}
+}
@@ -0,0 +1,128 @@
+package github
+
+import (
+	"sort"
+	"time"
+)
+
+// maxCombinedPinned is the number of pinned repositories kept when combining accounts
+const maxCombinedPinned = 6
+
+// CombineStats merges statistics from several connected accounts into one
+// profile. Counts are summed, contribution calendars are merged by date and
+// profile details come from the first account.
+func CombineStats(list []*UserProfileStats) *UserProfileStats {
+	if len(list) == 0 {
+		return nil
+	}
+	if len(list) == 1 {
+		return list[0]
+	}
+
+	first := list[0]
+	combined := &UserProfileStats{
+		Login:     first.Login,
+		Name:      first.Name,
+		AvatarURL: first.AvatarURL,
+		Bio:       first.Bio,
+		Period:    first.Period,
+		RateLimit: first.RateLimit,
+	}
+
+	dayCounts := make(map[string]int)
+	languages := NewLanguageAggregator(nil)
+
+	for _, stats := range list {
+		combined.TotalCommits += stats.TotalCommits
+		combined.TotalPullRequests += stats.TotalPullRequests
+		combined.TotalIssues += stats.TotalIssues
+		combined.TotalReviews += stats.TotalReviews
+		combined.TotalStarsEarned += stats.TotalStarsEarned
+		combined.ContributedStars += stats.ContributedStars
+		combined.StarsTruncated = combined.StarsTruncated || stats.StarsTruncated
+		combined.CalendarIncludesPrivate = combined.CalendarIncludesPrivate || stats.CalendarIncludesPrivate
+		combined.Followers += stats.Followers
+		combined.TotalPublicRepositories += stats.TotalPublicRepositories
+
+		if stats.IncludesPrivate && stats.Private != nil {
+			if combined.Private == nil {
+				combined.Private = &PrivateContributions{}
+			}
+			combined.IncludesPrivate = true
+			combined.Private.add(stats.Private)
+		}
+
+		for _, week := range stats.ContributionCalendar.Weeks {
+			for _, day := range week.ContributionDays {
+				dayCounts[day.Date] += day.ContributionCount
+			}
+		}
+
+		for _, lang := range stats.TopLanguages {
+			languages.AddStat(lang)
+		}
+
+		for _, repo := range stats.PinnedRepositories {
+			if len(combined.PinnedRepositories) < maxCombinedPinned {
+				combined.PinnedRepositories = append(combined.PinnedRepositories, repo)
+			}
+		}
+	}
+
+	combined.ContributionCalendar = CalendarFromCounts(dayCounts)
+	combined.TopLanguages = languages.Top(MaxTopLanguages)
+
+	return combined
+}
+
+// CalendarFromCounts builds a Sunday-first contribution calendar from daily counts
+func CalendarFromCounts(counts map[string]int) ContributionCalendar {
+	dates := make([]string, 0, len(counts))
+	for date := range counts {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	calendar := ContributionCalendar{Weeks: make([]ContributionWeek, 0)}
+	var week ContributionWeek
+
+	for _, date := range dates {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+		if parsed.Weekday() == time.Sunday && len(week.ContributionDays) > 0 {
+			calendar.Weeks = append(calendar.Weeks, week)
+			week = ContributionWeek{}
+		}
+
+		count := counts[date]
+		calendar.TotalContributions += count
+		week.ContributionDays = append(week.ContributionDays, ContributionDay{
+			Color:             calendarColor(count),
+			ContributionCount: count,
+			Date:              date,
+		})
+	}
+	if len(week.ContributionDays) > 0 {
+		calendar.Weeks = append(calendar.Weeks, week)
+	}
+
+	return calendar
+}
+
+// calendarColor approximates GitHub's calendar color for a contribution count
+func calendarColor(count int) string {
+	switch {
+	case count == 0:
+		return "#ebedf0"
+	case count < 3:
+		return "#9be9a8"
+	case count < 6:
+		return "#40c463"
+	case count < 10:
+		return "#30a14e"
+	default:
+		return "#216e39"
+	}
+}
@@ -0,0 +1,565 @@
+// Package githubtest provides a fake GitHub GraphQL server for testing code
+// that fetches GitHub profiles without calling api.github.com.
+package githubtest
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed fixtures/*.json
+var fixtureFiles embed.FS
+
+// Day is a single day of contributions in a fixture
+type Day struct {
+	Date         string `json:"date"`
+	Commits      int    `json:"commits"`
+	PullRequests int    `json:"pull_requests"`
+	Issues       int    `json:"issues"`
+	Reviews      int    `json:"reviews"`
+	Private      bool   `json:"private"` // Contributions were made to a private repository
+}
+
+// count returns the total contributions on the day
+func (d Day) count() int {
+	return d.Commits + d.PullRequests + d.Issues + d.Reviews
+}
+
+// Repo is a repository in a fixture
+type Repo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	URL         string         `json:"url"`
+	Stars       int            `json:"stars"`
+	Forks       int            `json:"forks"`
+	IsFork      bool           `json:"is_fork"`
+	OwnerType   string         `json:"owner_type"` // User or Organization
+	Languages   []RepoLanguage `json:"languages"`  // Largest first
+
+	Topics           []string `json:"topics"`
+	License          string   `json:"license"` // SPDX ID
+	PushedAt         string   `json:"pushed_at"`
+	OpenIssues       int      `json:"open_issues"`
+	OpenPullRequests int      `json:"open_pull_requests"`
+	Releases         int      `json:"releases"`
+	RecentCommits    int      `json:"recent_commits"`
+	IsArchived       bool     `json:"is_archived"`
+}
+
+// RepoLanguage is the size of a language in a fixture repository
+type RepoLanguage struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Size  int    `json:"size"`
+}
+
+// User is a GitHub user served by the fake server
+type User struct {
+	Login        string `json:"login"`
+	Name         string `json:"name"`
+	AvatarURL    string `json:"avatar_url"`
+	Bio          string `json:"bio"`
+	Token        string `json:"token"` // When set, requests must use this token
+	Followers    int    `json:"followers"`
+	Restricted   int    `json:"restricted"` // Restricted contributions in the current period
+	Days         []Day  `json:"days"`
+	Pinned       []Repo `json:"pinned"`
+	Repositories []Repo `json:"repositories"` // Owned repositories, most starred first
+	Contributed  []Repo `json:"contributed"`  // Other repositories contributed to, most starred first
+}
+
+// Fixture loads a bundled user fixture by name (e.g. "octocat")
+func Fixture(name string) (*User, error) {
+	data, err := fixtureFiles.ReadFile("fixtures/" + name + ".json")
+	if err != nil {
+		return nil, err
+	}
+	var user User
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Server is a fake GitHub GraphQL API
+type Server struct {
+	*httptest.Server
+
+	// PageSize is the number of repositories per page
+	PageSize int
+
+	// Budget is the number of queries allowed before the primary rate limit
+	// is hit; zero means unlimited
+	Budget int
+
+	// RetryAfter, when set, answers every query with a secondary rate limit
+	// asking clients to wait this long
+	RetryAfter time.Duration
+
+	// NodeLimit is the most contributions listed per repository, like the
+	// first: 100 GitHub allows
+	NodeLimit int
+
+	mu       sync.Mutex
+	users    map[string]*User
+	requests int
+}
+
+// rateLimitReset is when the fake rate limit resets
+var rateLimitReset = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// NewServer starts a fake GraphQL server serving the given users. Its URL
+// can be used as the endpoint of a github.Client.
+func NewServer(users ...*User) *Server {
+	s := &Server{
+		PageSize:  100,
+		NodeLimit: 100,
+		users:     make(map[string]*User),
+	}
+	for _, u := range users {
+		s.AddUser(u)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// AddUser adds or replaces a user served by the server
+func (s *Server) AddUser(u *User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[strings.ToLower(u.Login)] = u
+}
+
+// Requests returns the number of GraphQL requests received
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// graphQLRequest is the body of a GraphQL request
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// handle answers a GraphQL request with data shaped like the query
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	requests, budget, retryAfter := s.requests, s.Budget, s.RetryAfter
+	s.mu.Unlock()
+
+	if retryAfter > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"You have exceeded a secondary rate limit"}`))
+		return
+	}
+
+	// Simulate the primary rate limit once the budget is spent
+	if budget > 0 && requests > budget {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(rateLimitReset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+		return
+	}
+
+	var req graphQLRequest
+	if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&req) != nil {
+		http.Error(w, "invalid GraphQL request", http.StatusBadRequest)
+		return
+	}
+
+	username, _ := req.Variables["username"].(string)
+	s.mu.Lock()
+	user, ok := s.users[strings.ToLower(username)]
+	s.mu.Unlock()
+
+	if !ok {
+		writeErrors(w, "Could not resolve to a User with the login of '"+username+"'.")
+		return
+	}
+	if user.Token != "" && r.Header.Get("Authorization") != "Bearer "+user.Token {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"Bad credentials"}`))
+		return
+	}
+
+	cursor, _ := req.Variables["cursor"].(string)
+	result := map[string]interface{}{}
+
+	switch {
+	case strings.Contains(req.Query, "pinnedItems"):
+		result = s.profile(user, req.Variables)
+	case strings.Contains(req.Query, "repositoryTopics"):
+		result["repositories"] = s.detailedRepositoryPage(user.Repositories, cursor)
+	case strings.Contains(req.Query, "repositoriesContributedTo"):
+		result["repositoriesContributedTo"] = s.repositoryPage(user.Contributed, cursor, false)
+	case strings.Contains(req.Query, "repositories("):
+		result["repositories"] = s.repositoryPage(user.Repositories, cursor, true)
+	case strings.Contains(req.Query, "occurredAt"):
+		result["contributionsCollection"] = s.contributionBreakdown(user, req.Variables)
+	case strings.Contains(req.Query, "contributionsCollection"):
+		result["contributionsCollection"] = contributions(user, req.Variables)
+	default:
+		writeErrors(w, "unsupported query")
+		return
+	}
+
+	data := map[string]interface{}{"user": result}
+	if strings.Contains(req.Query, "rateLimit") {
+		data["rateLimit"] = rateLimit(requests, budget)
+	}
+
+	writeJSON(w, map[string]interface{}{"data": data})
+}
+
+// rateLimit builds the rateLimit field after the given number of requests
+func rateLimit(requests, budget int) map[string]interface{} {
+	limit := 5000
+	if budget > 0 {
+		limit = budget
+	}
+	return map[string]interface{}{
+		"limit":     limit,
+		"remaining": limit - requests,
+		"cost":      1,
+		"resetAt":   rateLimitReset.Format(time.RFC3339),
+	}
+}
+
+// profile builds the response to the main profile query
+func (s *Server) profile(user *User, variables map[string]interface{}) map[string]interface{} {
+	pinned := make([]map[string]interface{}, 0, len(user.Pinned))
+	for _, repo := range user.Pinned {
+		pinned = append(pinned, repositoryDetails(repo))
+	}
+
+	return map[string]interface{}{
+		"login":                   user.Login,
+		"name":                    user.Name,
+		"avatarUrl":               user.AvatarURL,
+		"bio":                     user.Bio,
+		"followers":               map[string]interface{}{"totalCount": user.Followers},
+		"contributionsCollection": contributions(user, variables),
+		"pinnedItems":             map[string]interface{}{"nodes": pinned},
+		"repositories":            s.repositoryPage(user.Repositories, "", true),
+	}
+}
+
+// repositoryDetails builds a repository node with details
+func repositoryDetails(repo Repo) map[string]interface{} {
+	language := map[string]interface{}{"name": "", "color": ""}
+	if len(repo.Languages) > 0 {
+		language = map[string]interface{}{"name": repo.Languages[0].Name, "color": repo.Languages[0].Color}
+	}
+
+	topics := make([]map[string]interface{}, 0, len(repo.Topics))
+	for _, topic := range repo.Topics {
+		topics = append(topics, map[string]interface{}{"topic": map[string]interface{}{"name": topic}})
+	}
+
+	var license interface{}
+	if repo.License != "" {
+		license = map[string]interface{}{"spdxId": repo.License, "name": repo.License}
+	}
+
+	var pushedAt interface{}
+	if repo.PushedAt != "" {
+		pushedAt = repo.PushedAt
+	}
+
+	return map[string]interface{}{
+		"name":             repo.Name,
+		"description":      repo.Description,
+		"url":              repo.URL,
+		"stargazerCount":   repo.Stars,
+		"forkCount":        repo.Forks,
+		"isFork":           repo.IsFork,
+		"isArchived":       repo.IsArchived,
+		"pushedAt":         pushedAt,
+		"primaryLanguage":  language,
+		"repositoryTopics": map[string]interface{}{"nodes": topics},
+		"licenseInfo":      license,
+		"issues":           map[string]interface{}{"totalCount": repo.OpenIssues},
+		"pullRequests":     map[string]interface{}{"totalCount": repo.OpenPullRequests},
+		"releases":         map[string]interface{}{"totalCount": repo.Releases},
+		"defaultBranchRef": map[string]interface{}{
+			"target": map[string]interface{}{
+				"history": map[string]interface{}{"totalCount": repo.RecentCommits},
+			},
+		},
+	}
+}
+
+// detailedRepositoryPage builds a page of repositories with details
+func (s *Server) detailedRepositoryPage(repos []Repo, cursor string) map[string]interface{} {
+	start, end := s.pageBounds(len(repos), cursor)
+
+	nodes := make([]map[string]interface{}, 0, end-start)
+	for _, repo := range repos[start:end] {
+		nodes = append(nodes, repositoryDetails(repo))
+	}
+
+	return map[string]interface{}{
+		"pageInfo": pageInfo(end, len(repos)),
+		"nodes":    nodes,
+	}
+}
+
+// repositoryPage builds a page of a repository connection. Cursors are the
+// index of the first repository on the next page.
+func (s *Server) repositoryPage(repos []Repo, cursor string, owned bool) map[string]interface{} {
+	start, end := s.pageBounds(len(repos), cursor)
+
+	nodes := make([]map[string]interface{}, 0, end-start)
+	for _, repo := range repos[start:end] {
+		node := map[string]interface{}{"stargazerCount": repo.Stars}
+		if owned {
+			edges := make([]map[string]interface{}, 0, len(repo.Languages))
+			for _, lang := range repo.Languages {
+				edges = append(edges, map[string]interface{}{
+					"size": lang.Size,
+					"node": map[string]interface{}{"name": lang.Name, "color": lang.Color},
+				})
+			}
+			node["isFork"] = repo.IsFork
+			node["languages"] = map[string]interface{}{"edges": edges}
+		} else {
+			ownerType := repo.OwnerType
+			if ownerType == "" {
+				ownerType = "User"
+			}
+			node["owner"] = map[string]interface{}{"__typename": ownerType}
+		}
+		nodes = append(nodes, node)
+	}
+
+	page := map[string]interface{}{
+		"pageInfo": pageInfo(end, len(repos)),
+		"nodes":    nodes,
+	}
+	if owned {
+		page["totalCount"] = len(repos)
+	}
+	return page
+}
+
+// pageBounds returns the range of the page starting at a cursor
+func (s *Server) pageBounds(total int, cursor string) (int, int) {
+	start, _ := strconv.Atoi(cursor)
+	if start > total {
+		start = total
+	}
+	end := start + s.PageSize
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// pageInfo builds the cursor information for a page ending at end
+func pageInfo(end, total int) map[string]interface{} {
+	return map[string]interface{}{
+		"hasNextPage": end < total,
+		"endCursor":   strconv.Itoa(end),
+	}
+}
+
+// daysInRange returns the fixture days within the query's from/to range
+func daysInRange(user *User, variables map[string]interface{}) []Day {
+	from, _ := time.Parse(time.RFC3339, stringVar(variables, "from"))
+	to, _ := time.Parse(time.RFC3339, stringVar(variables, "to"))
+
+	var days []Day
+	for _, day := range user.Days {
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil || (!from.IsZero() && date.Before(from.Truncate(24*time.Hour))) || (!to.IsZero() && date.After(to)) {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// contributions builds a contributions collection for the days within the
+// query's from/to range
+func contributions(user *User, variables map[string]interface{}) map[string]interface{} {
+	var commits, pullRequests, issues, reviews, total int
+	var weeks []map[string]interface{}
+	var week []map[string]interface{}
+	byRepository := newContributionRepos()
+
+	for _, day := range daysInRange(user, variables) {
+		date, _ := time.Parse("2006-01-02", day.Date)
+
+		commits += day.Commits
+		pullRequests += day.PullRequests
+		issues += day.Issues
+		reviews += day.Reviews
+		total += day.count()
+		byRepository.add(day)
+
+		// Weeks start on Sunday
+		if date.Weekday() == time.Sunday && len(week) > 0 {
+			weeks = append(weeks, map[string]interface{}{"contributionDays": week})
+			week = nil
+		}
+		week = append(week, map[string]interface{}{
+			"color":             dayColor(day.count()),
+			"contributionCount": day.count(),
+			"date":              day.Date,
+		})
+	}
+	if len(week) > 0 {
+		weeks = append(weeks, map[string]interface{}{"contributionDays": week})
+	}
+
+	return map[string]interface{}{
+		"totalCommitContributions":                   commits,
+		"totalPullRequestContributions":              pullRequests,
+		"totalIssueContributions":                    issues,
+		"totalPullRequestReviewContributions":        reviews,
+		"restrictedContributionsCount":               user.Restricted,
+		"commitContributionsByRepository":            byRepository.list("commits", 0),
+		"pullRequestContributionsByRepository":       byRepository.list("pullRequests", 0),
+		"issueContributionsByRepository":             byRepository.list("issues", 0),
+		"pullRequestReviewContributionsByRepository": byRepository.list("reviews", 0),
+		"contributionCalendar": map[string]interface{}{
+			"totalContributions": total,
+			"weeks":              weeks,
+		},
+	}
+}
+
+// contributionBreakdown lists the contributions to each repository within
+// the query's from/to range
+func (s *Server) contributionBreakdown(user *User, variables map[string]interface{}) map[string]interface{} {
+	byRepository := newContributionRepos()
+	for _, day := range daysInRange(user, variables) {
+		byRepository.add(day)
+	}
+
+	s.mu.Lock()
+	limit := s.NodeLimit
+	s.mu.Unlock()
+
+	return map[string]interface{}{
+		"commitContributionsByRepository":            byRepository.list("commits", limit),
+		"pullRequestContributionsByRepository":       byRepository.list("pullRequests", limit),
+		"issueContributionsByRepository":             byRepository.list("issues", limit),
+		"pullRequestReviewContributionsByRepository": byRepository.list("reviews", limit),
+	}
+}
+
+// contributionRepos groups fixture contributions into one public and one
+// private repository per contribution type
+type contributionRepos map[string]map[bool][]map[string]interface{}
+
+// newContributionRepos creates an empty grouping
+func newContributionRepos() contributionRepos {
+	return contributionRepos{}
+}
+
+// add records a day's contributions; commits are one node per day with a
+// commit count, other types one node per contribution
+func (c contributionRepos) add(day Day) {
+	occurredAt := day.Date + "T00:00:00Z"
+	if day.Commits > 0 {
+		c.append("commits", day.Private, map[string]interface{}{"occurredAt": occurredAt, "commitCount": day.Commits})
+	}
+	for kind, count := range map[string]int{"pullRequests": day.PullRequests, "issues": day.Issues, "reviews": day.Reviews} {
+		for i := 0; i < count; i++ {
+			c.append(kind, day.Private, map[string]interface{}{"occurredAt": occurredAt})
+		}
+	}
+}
+
+// append adds a contribution node to a repository
+func (c contributionRepos) append(kind string, private bool, node map[string]interface{}) {
+	if c[kind] == nil {
+		c[kind] = make(map[bool][]map[string]interface{})
+	}
+	c[kind][private] = append(c[kind][private], node)
+}
+
+// list returns the repositories contributed to for a contribution type.
+// With a node limit, each repository lists up to that many contributions;
+// without one, only the counts are returned.
+func (c contributionRepos) list(kind string, nodeLimit int) []map[string]interface{} {
+	repos := make([]map[string]interface{}, 0, 2)
+	for _, private := range []bool{false, true} {
+		nodes := c[kind][private]
+		if len(nodes) == 0 {
+			continue
+		}
+		total := len(nodes)
+		if kind == "commits" {
+			total = 0
+			for _, node := range nodes {
+				total += node["commitCount"].(int)
+			}
+		}
+
+		contributions := map[string]interface{}{"totalCount": total}
+		if nodeLimit > 0 {
+			if len(nodes) > nodeLimit {
+				nodes = nodes[:nodeLimit]
+			}
+			contributions["nodes"] = nodes
+		}
+		repos = append(repos, map[string]interface{}{
+			"repository":    map[string]interface{}{"isPrivate": private},
+			"contributions": contributions,
+		})
+	}
+	return repos
+}
+
+// dayColor returns GitHub's calendar color for a contribution count
+func dayColor(count int) string {
+	switch {
+	case count == 0:
+		return "#ebedf0"
+	case count < 3:
+		return "#9be9a8"
+	case count < 6:
+		return "#40c463"
+	case count < 10:
+		return "#30a14e"
+	default:
+		return "#216e39"
+	}
+}
+
+// stringVar returns a string query variable
+func stringVar(variables map[string]interface{}, name string) string {
+	v, _ := variables[name].(string)
+	return v
+}
+
+// writeErrors writes a GraphQL error response
+func writeErrors(w http.ResponseWriter, messages ...string) {
+	errs := make([]map[string]interface{}, 0, len(messages))
+	for _, m := range messages {
+		errs = append(errs, map[string]interface{}{"message": m})
+	}
+	writeJSON(w, map[string]interface{}{"data": nil, "errors": errs})
+}
+
+// writeJSON writes a 200 JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,66 @@
+package github
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// DefaultHost is the host of the public GitHub instance
+const DefaultHost = "github.com"
+
+// enterpriseHosts are the GitHub Enterprise Server hosts users may connect.
+// Tokens are sent to the host, so arbitrary hosts are never allowed.
+var enterpriseHosts = map[string]bool{}
+
+// ErrHostNotAllowed is returned for GitHub hosts that are not configured
+var ErrHostNotAllowed = errors.New("GitHub host is not an allowed enterprise host")
+
+// SetEnterpriseHosts sets the GitHub Enterprise Server hosts users may connect
+func SetEnterpriseHosts(hosts []string) {
+	enterpriseHosts = make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		if normalized := StripHost(host); normalized != "" {
+			enterpriseHosts[normalized] = true
+		}
+	}
+}
+
+// NormalizeHost converts a host or URL (e.g. "https://github.example.com/")
+// into a bare lowercase host, checking it is github.com or an allowed
+// enterprise host. An empty host means github.com.
+func NormalizeHost(host string) (string, error) {
+	normalized := StripHost(host)
+	if normalized == "" || normalized == DefaultHost || normalized == "api.github.com" {
+		return DefaultHost, nil
+	}
+	if !enterpriseHosts[normalized] {
+		return "", ErrHostNotAllowed
+	}
+	return normalized, nil
+}
+
+// EndpointForHost returns the GraphQL API endpoint for a GitHub host
+func EndpointForHost(host string) string {
+	if host == "" || host == DefaultHost {
+		return DefaultEndpoint
+	}
+	// GitHub Enterprise Server serves GraphQL under /api/graphql
+	return "https://" + host + "/api/graphql"
+}
+
+// StripHost returns the bare lowercase host of a host or URL
+func StripHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" {
+		return ""
+	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
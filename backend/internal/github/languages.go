@@ -0,0 +1,113 @@
+package github
+
+import (
+	"sort"
+	"strings"
+)
+
+// MaxTopLanguages is the number of languages returned in the profile
+const MaxTopLanguages = 8
+
+// defaultIgnoredLanguages are left out of language breakdowns unless a
+// request provides its own list
+var defaultIgnoredLanguages []string
+
+// SetIgnoredLanguages sets the languages left out of language breakdowns by default
+func SetIgnoredLanguages(languages []string) {
+	defaultIgnoredLanguages = languages
+}
+
+// LanguageStat describes how much of a user's code is written in a language
+type LanguageStat struct {
+	Name         string  `json:"name"`
+	Color        string  `json:"color"`
+	Bytes        int     `json:"bytes"`
+	SharePercent float64 `json:"share_percent"`
+	RepoCount    int     `json:"repo_count"`
+}
+
+// LanguageAggregator accumulates language sizes across repositories
+type LanguageAggregator struct {
+	ignored map[string]bool
+	stats   map[string]*LanguageStat
+	seen    map[string]bool // Languages seen in the current repository
+}
+
+// NewLanguageAggregator creates an aggregator that skips the ignored languages
+func NewLanguageAggregator(ignored []string) *LanguageAggregator {
+	a := &LanguageAggregator{
+		ignored: make(map[string]bool, len(ignored)),
+		stats:   make(map[string]*LanguageStat),
+		seen:    make(map[string]bool),
+	}
+	for _, name := range ignored {
+		a.ignored[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	return a
+}
+
+// Add records the size of a language in the current repository
+func (a *LanguageAggregator) Add(name, color string, size int) {
+	if name == "" || a.ignored[strings.ToLower(name)] {
+		return
+	}
+
+	stat, ok := a.stats[name]
+	if !ok {
+		stat = &LanguageStat{Name: name, Color: color}
+		a.stats[name] = stat
+	}
+	stat.Bytes += size
+
+	if !a.seen[name] {
+		a.seen[name] = true
+		stat.RepoCount++
+	}
+}
+
+// AddStat adds an already aggregated language, e.g. from another account
+func (a *LanguageAggregator) AddStat(lang LanguageStat) {
+	if lang.Name == "" || a.ignored[strings.ToLower(lang.Name)] {
+		return
+	}
+
+	stat, ok := a.stats[lang.Name]
+	if !ok {
+		stat = &LanguageStat{Name: lang.Name, Color: lang.Color}
+		a.stats[lang.Name] = stat
+	}
+	stat.Bytes += lang.Bytes
+	stat.RepoCount += lang.RepoCount
+}
+
+// FinishRepository marks the end of the current repository
+func (a *LanguageAggregator) FinishRepository() {
+	a.seen = make(map[string]bool)
+}
+
+// Top returns the largest languages by size with their share of all bytes
+func (a *LanguageAggregator) Top(limit int) []LanguageStat {
+	total := 0
+	list := make([]LanguageStat, 0, len(a.stats))
+	for _, stat := range a.stats {
+		total += stat.Bytes
+		list = append(list, *stat)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Bytes != list[j].Bytes {
+			return list[i].Bytes > list[j].Bytes
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	for i := range list {
+		if total > 0 {
+			list[i].SharePercent = roundOneDecimal(float64(list[i].Bytes) * 100 / float64(total))
+		}
+	}
+	return list
+}
@@ -0,0 +1,151 @@
+package github
+
+import (
+	"math"
+	"sort"
+)
+
+// PercentileInfo describes where a user stands among all registered users
+type PercentileInfo struct {
+	Overall    float64            `json:"overall"`
+	Metrics    map[string]float64 `json:"metrics"`
+	SampleSize int                `json:"sample_size"`
+}
+
+// Rank modes supported by the profile endpoint
+const (
+	RankModeAbsolute = "absolute"
+	RankModeCurve    = "curve"
+)
+
+// curveTier is a rank tier assigned by percentile band in curve mode
+type curveTier struct {
+	rank          string
+	minPercentile float64
+}
+
+// Percentile bands for curve mode, from highest to lowest
+var curveTiers = []curveTier{
+	{"S+", 99},
+	{"S", 95},
+	{"A+", 85},
+	{"A", 65},
+	{"B+", 40},
+	{"B", 15},
+	{"C", 0},
+}
+
+// Percentile returns the percentage of values in the population that are
+// less than or equal to value
+func Percentile(value int, population []int) float64 {
+	if len(population) == 0 {
+		return 0
+	}
+
+	atOrBelow := 0
+	for _, v := range population {
+		if v <= value {
+			atOrBelow++
+		}
+	}
+
+	pct := float64(atOrBelow) * 100 / float64(len(population))
+	return roundOneDecimal(pct)
+}
+
+// CalculatePercentiles computes overall and per-metric percentiles for stats
+// against a population of other users' statistics
+func CalculatePercentiles(stats UserProfileStats, population []UserProfileStats) PercentileInfo {
+	scores := make([]int, 0, len(population))
+	for _, p := range population {
+		scores = append(scores, Score(p))
+	}
+
+	info := PercentileInfo{
+		Overall:    Percentile(Score(stats), scores),
+		Metrics:    make(map[string]float64),
+		SampleSize: len(population),
+	}
+
+	for i, m := range scoredMetrics(stats) {
+		values := make([]int, 0, len(population))
+		for _, p := range population {
+			values = append(values, scoredMetrics(p)[i].value)
+		}
+		info.Metrics[m.name] = Percentile(m.value, values)
+	}
+
+	return info
+}
+
+// CalculateCurveRank assigns a rank tier by percentile band rather than by
+// absolute score thresholds. The next rank threshold is the lowest score in
+// the population that would place the user in the next band.
+func CalculateCurveRank(stats UserProfileStats, population []UserProfileStats) RankInfo {
+	metrics := scoredMetrics(stats)
+	totalScore := Score(stats)
+
+	scores := make([]int, 0, len(population))
+	for _, p := range population {
+		scores = append(scores, Score(p))
+	}
+	sort.Ints(scores)
+
+	pct := Percentile(totalScore, scores)
+
+	// Find the current band
+	tierIndex := len(curveTiers) - 1
+	for i, t := range curveTiers {
+		if pct >= t.minPercentile {
+			tierIndex = i
+			break
+		}
+	}
+	current := curveTiers[tierIndex]
+
+	info := RankInfo{
+		Rank:            current.rank,
+		Score:           totalScore,
+		ProgressPercent: 100,
+		Breakdown:       buildBreakdown(metrics, totalScore),
+	}
+
+	if tierIndex == 0 {
+		// Maximum rank achieved
+		return info
+	}
+
+	next := curveTiers[tierIndex-1]
+	info.NextRank = next.rank
+
+	// Lowest score that would reach the next band
+	for _, s := range scores {
+		if s > totalScore && Percentile(s, scores) >= next.minPercentile {
+			info.NextRankThreshold = s
+			break
+		}
+	}
+
+	// Calculate progress within the current band
+	progress := (pct - current.minPercentile) * 100 / (next.minPercentile - current.minPercentile)
+	info.ProgressPercent = clampPercent(int(progress))
+	info.Suggestions = buildSuggestions(metrics, totalScore, info.NextRank, info.NextRankThreshold)
+
+	return info
+}
+
+// roundOneDecimal rounds a value to one decimal place
+func roundOneDecimal(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// clampPercent clamps a percentage between 0 and 100
+func clampPercent(p int) int {
+	if p < 0 {
+		return 0
+	}
+	if p > 100 {
+		return 100
+	}
+	return p
+}
@@ -0,0 +1,268 @@
+package github
+
+import (
+	"context"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// PrivateContributions are the contributions to private repositories included
+// in a profile's totals and contribution calendar
+type PrivateContributions struct {
+	Commits      int `json:"commits"`
+	PullRequests int `json:"pull_requests"`
+	Issues       int `json:"issues"`
+	Reviews      int `json:"reviews"`
+	// Restricted are contributions in repositories the token can't see, such
+	// as SAML-protected organizations. GitHub doesn't break them down by type,
+	// so they are counted as commits.
+	Restricted int `json:"restricted"`
+	// Days are private contributions per date, by the day they occurred
+	Days map[string]int `json:"days,omitempty"`
+	// DaysIncomplete is set when some private contributions are missing from
+	// Days, either restricted ones or ones past where GitHub cut the
+	// breakdown short, so they can't be removed from the calendar
+	DaysIncomplete bool `json:"days_incomplete,omitempty"`
+}
+
+// add accumulates another account's private contributions
+func (p *PrivateContributions) add(other *PrivateContributions) {
+	p.Commits += other.Commits
+	p.PullRequests += other.PullRequests
+	p.Issues += other.Issues
+	p.Reviews += other.Reviews
+	p.Restricted += other.Restricted
+	p.DaysIncomplete = p.DaysIncomplete || other.DaysIncomplete
+	for date, count := range other.Days {
+		if p.Days == nil {
+			p.Days = make(map[string]int)
+		}
+		p.Days[date] += count
+	}
+}
+
+// maxContributionRepositories is the most repositories GitHub lists per
+// contribution type, and maxContributionNodes the most contributions it
+// returns per repository
+const (
+	maxContributionRepositories = 100
+	maxContributionNodes        = 100
+)
+
+// maxBreakdownQueries bounds the queries made to find the days of a time
+// range's private contributions
+const maxBreakdownQueries = 16
+
+// repositoryContributionTotals is the number of contributions to one
+// repository, enough to tell whether any were private
+type repositoryContributionTotals struct {
+	Repository struct {
+		IsPrivate githubv4.Boolean
+	}
+	Contributions struct {
+		TotalCount githubv4.Int
+	}
+}
+
+// contributionNodes are the GraphQL contributions to one repository
+type contributionNodes struct {
+	Repository struct {
+		IsPrivate githubv4.Boolean
+	}
+	Contributions struct {
+		TotalCount githubv4.Int
+		Nodes      []struct {
+			OccurredAt githubv4.DateTime
+		}
+	} `graphql:"contributions(first: 100)"`
+}
+
+// commitContributionNodes are the GraphQL commit contributions to one
+// repository; each node is a day's commits
+type commitContributionNodes struct {
+	Repository struct {
+		IsPrivate githubv4.Boolean
+	}
+	Contributions struct {
+		TotalCount githubv4.Int
+		Nodes      []struct {
+			OccurredAt  githubv4.DateTime
+			CommitCount githubv4.Int
+		}
+	} `graphql:"contributions(first: 100)"`
+}
+
+// PrivateBreakdownQuery fetches the contributions to each repository within a
+// time range, to find the days private contributions occurred on
+type PrivateBreakdownQuery struct {
+	RateLimit rateLimitInfo
+	User      struct {
+		ContributionsCollection struct {
+			CommitContributionsByRepository            []commitContributionNodes `graphql:"commitContributionsByRepository(maxRepositories: 100)"`
+			PullRequestContributionsByRepository       []contributionNodes       `graphql:"pullRequestContributionsByRepository(maxRepositories: 100)"`
+			IssueContributionsByRepository             []contributionNodes       `graphql:"issueContributionsByRepository(maxRepositories: 100)"`
+			PullRequestReviewContributionsByRepository []contributionNodes       `graphql:"pullRequestReviewContributionsByRepository(maxRepositories: 100)"`
+		} `graphql:"contributionsCollection(from: $from, to: $to)"`
+	} `graphql:"user(login: $username)"`
+}
+
+// privateTotals sums a collection's contributions to private repositories.
+// It reports false when a list reached GitHub's repository limit, so some
+// private repositories may be missing.
+func privateTotals(collection contributionsCollection) (PrivateContributions, bool) {
+	var private PrivateContributions
+	complete := true
+	for _, list := range []struct {
+		repos []repositoryContributionTotals
+		total *int
+	}{
+		{collection.CommitContributionsByRepository, &private.Commits},
+		{collection.PullRequestContributionsByRepository, &private.PullRequests},
+		{collection.IssueContributionsByRepository, &private.Issues},
+		{collection.PullRequestReviewContributionsByRepository, &private.Reviews},
+	} {
+		complete = complete && len(list.repos) < maxContributionRepositories
+		for _, repo := range list.repos {
+			if bool(repo.Repository.IsPrivate) {
+				*list.total += int(repo.Contributions.TotalCount)
+			}
+		}
+	}
+	return private, complete
+}
+
+// any reports whether there are private contributions to break down by day
+func (p PrivateContributions) any() bool {
+	return p.Commits+p.PullRequests+p.Issues+p.Reviews > 0
+}
+
+// privateBreakdown sums a breakdown's contributions to private repositories
+// by day. It reports false when GitHub cut a list short, either at its
+// repository limit or at the first page of a repository's contributions.
+func privateBreakdown(query *PrivateBreakdownQuery) (PrivateContributions, bool) {
+	collection := query.User.ContributionsCollection
+	private := PrivateContributions{Days: make(map[string]int)}
+	complete := len(collection.CommitContributionsByRepository) < maxContributionRepositories
+
+	for _, repo := range collection.CommitContributionsByRepository {
+		if !bool(repo.Repository.IsPrivate) {
+			continue
+		}
+		// The total counts commits, while each node is a day of them
+		counted := 0
+		for _, node := range repo.Contributions.Nodes {
+			private.Days[node.OccurredAt.Format("2006-01-02")] += int(node.CommitCount)
+			counted += int(node.CommitCount)
+		}
+		private.Commits += int(repo.Contributions.TotalCount)
+		complete = complete && counted >= int(repo.Contributions.TotalCount)
+	}
+
+	for _, list := range []struct {
+		repos []contributionNodes
+		total *int
+	}{
+		{collection.PullRequestContributionsByRepository, &private.PullRequests},
+		{collection.IssueContributionsByRepository, &private.Issues},
+		{collection.PullRequestReviewContributionsByRepository, &private.Reviews},
+	} {
+		complete = complete && len(list.repos) < maxContributionRepositories
+		for _, repo := range list.repos {
+			if !bool(repo.Repository.IsPrivate) {
+				continue
+			}
+			*list.total += int(repo.Contributions.TotalCount)
+			for _, node := range repo.Contributions.Nodes {
+				private.Days[node.OccurredAt.Format("2006-01-02")]++
+			}
+			complete = complete && len(repo.Contributions.Nodes) >= int(repo.Contributions.TotalCount)
+		}
+	}
+
+	return private, complete
+}
+
+// fetchPrivateBreakdown finds the days of a time range's private
+// contributions. GitHub returns at most 100 contributions per repository, so
+// ranges with more are split in half and fetched separately, within a budget
+// of queries. Ranges that can't be split further are marked DaysIncomplete.
+func fetchPrivateBreakdown(ctx context.Context, client *session, username string, period TimeRange, budget *int) (PrivateContributions, error) {
+	*budget--
+	var query PrivateBreakdownQuery
+	err := client.query(ctx, &query, map[string]interface{}{
+		"username": githubv4.String(username),
+		"from":     githubv4.DateTime{Time: period.From},
+		"to":       githubv4.DateTime{Time: period.To},
+	}, func() rateLimitInfo { return query.RateLimit })
+	if err != nil {
+		return PrivateContributions{}, err
+	}
+
+	private, complete := privateBreakdown(&query)
+	if complete || *budget < 2 || period.To.Sub(period.From) < 48*time.Hour {
+		private.DaysIncomplete = !complete
+		return private, nil
+	}
+
+	// Split at a day boundary so each day is counted in one half
+	mid := period.From.Add(period.To.Sub(period.From) / 2).UTC().Truncate(24 * time.Hour)
+	if !mid.After(period.From) {
+		mid = mid.Add(24 * time.Hour)
+	}
+	result := PrivateContributions{Days: make(map[string]int)}
+	for _, half := range []TimeRange{{From: period.From, To: mid.Add(-time.Second)}, {From: mid, To: period.To}} {
+		halfPrivate, err := fetchPrivateBreakdown(ctx, client, username, half, budget)
+		if err != nil {
+			return PrivateContributions{}, err
+		}
+		result.add(&halfPrivate)
+	}
+	return result, nil
+}
+
+// WithoutPrivate returns a copy of the stats with private contributions
+// removed from the totals and the contribution calendar. Days that aren't
+// known stay in the calendar, which is then marked CalendarIncludesPrivate.
+func (s UserProfileStats) WithoutPrivate() UserProfileStats {
+	if !s.IncludesPrivate || s.Private == nil {
+		return s
+	}
+
+	p := s.Private
+	s.TotalCommits = nonNegative(s.TotalCommits - p.Commits - p.Restricted)
+	s.TotalPullRequests = nonNegative(s.TotalPullRequests - p.PullRequests)
+	s.TotalIssues = nonNegative(s.TotalIssues - p.Issues)
+	s.TotalReviews = nonNegative(s.TotalReviews - p.Reviews)
+	s.ContributionCalendar = subtractDays(s.ContributionCalendar, p.Days)
+	s.CalendarIncludesPrivate = s.CalendarIncludesPrivate || p.DaysIncomplete
+	s.IncludesPrivate = false
+	s.Private = nil
+	return s
+}
+
+// subtractDays returns a copy of a calendar with daily counts removed
+func subtractDays(calendar ContributionCalendar, days map[string]int) ContributionCalendar {
+	result := ContributionCalendar{Weeks: make([]ContributionWeek, 0, len(calendar.Weeks))}
+	for _, week := range calendar.Weeks {
+		copied := ContributionWeek{ContributionDays: make([]ContributionDay, 0, len(week.ContributionDays))}
+		for _, day := range week.ContributionDays {
+			if removed := days[day.Date]; removed > 0 {
+				day.ContributionCount = nonNegative(day.ContributionCount - removed)
+				day.Color = calendarColor(day.ContributionCount)
+			}
+			result.TotalContributions += day.ContributionCount
+			copied.ContributionDays = append(copied.ContributionDays, day)
+		}
+		result.Weeks = append(result.Weeks, copied)
+	}
+	return result
+}
+
+// nonNegative clamps negative values to zero
+func nonNegative(v int) int {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
@@ -1,5 +1,10 @@
 package github
 
+import (
+	"fmt"
+	"sort"
+)
+
 // RankInfo represents the developer rank and progress information
 type RankInfo struct {
 	Rank              string `json:"rank"`
@@ -7,6 +12,26 @@ type RankInfo struct {
 	NextRank          string `json:"next_rank,omitempty"`
 	NextRankThreshold int    `json:"next_rank_threshold,omitempty"`
 	ProgressPercent   int    `json:"progress_percent"`
+
+	Breakdown   []MetricBreakdown `json:"breakdown"`
+	Suggestions []RankSuggestion  `json:"suggestions,omitempty"`
+}
+
+// MetricBreakdown describes how a single metric contributes to the total score
+type MetricBreakdown struct {
+	Metric       string  `json:"metric"`
+	Value        int     `json:"value"`
+	Weight       int     `json:"weight"`
+	Points       int     `json:"points"`
+	SharePercent float64 `json:"share_percent"`
+}
+
+// RankSuggestion describes how much of a single metric would close the gap
+// to the next rank on its own
+type RankSuggestion struct {
+	Metric       string `json:"metric"`
+	AmountNeeded int    `json:"amount_needed"`
+	Message      string `json:"message"`
 }
 
 // Rank tier thresholds
@@ -19,25 +44,72 @@ const (
 	ThresholdB     = 50
 )
 
+// Ranks lists the rank tiers from highest to lowest
+var Ranks = []string{"S+", "S", "A+", "A", "B+", "B", "C"}
+
+// Score weights for each metric
+const (
+	WeightCommits     = 2
+	WeightPRs         = 3
+	WeightIssues      = 1
+	WeightReviews     = 2
+	WeightStarsEarned = 4
+	WeightFollowers   = 1
+)
+
+// scoredMetric pairs a metric's name and label with its weight and value
+type scoredMetric struct {
+	name   string
+	label  string
+	weight int
+	value  int
+}
+
+// scoredMetrics returns the weighted metrics that make up the score, in display order
+func scoredMetrics(stats UserProfileStats) []scoredMetric {
+	return []scoredMetric{
+		{"commits", "commits", WeightCommits, stats.TotalCommits},
+		{"pull_requests", "pull requests", WeightPRs, stats.TotalPullRequests},
+		{"issues", "issues", WeightIssues, stats.TotalIssues},
+		{"reviews", "reviews", WeightReviews, stats.TotalReviews},
+		{"stars", "stars earned", WeightStarsEarned, stats.TotalStarsEarned},
+		{"followers", "followers", WeightFollowers, stats.Followers},
+	}
+}
+
+// MetricNames returns the names of the scored metrics in display order
+func MetricNames() []string {
+	metrics := scoredMetrics(UserProfileStats{})
+	names := make([]string, len(metrics))
+	for i, m := range metrics {
+		names[i] = m.name
+	}
+	return names
+}
+
+// MetricValue returns the raw value of a scored metric by name
+func MetricValue(stats UserProfileStats, metric string) (int, bool) {
+	for _, m := range scoredMetrics(stats) {
+		if m.name == metric {
+			return m.value, true
+		}
+	}
+	return 0, false
+}
+
+// Score calculates the weighted score for a set of GitHub statistics
+func Score(stats UserProfileStats) int {
+	total := 0
+	for _, m := range scoredMetrics(stats) {
+		total += m.value * m.weight
+	}
+	return total
+}
+
 // CalculateRank calculates a developer rank based on GitHub statistics
 func CalculateRank(stats UserProfileStats) RankInfo {
-	// Define weights for each metric
-	const (
-		WeightCommits     = 2
-		WeightPRs         = 3
-		WeightIssues      = 1
-		WeightReviews     = 2
-		WeightStarsEarned = 4
-		WeightFollowers   = 1
-	)
-
-	// Calculate total score
-	totalScore := (stats.TotalCommits * WeightCommits) +
-		(stats.TotalPullRequests * WeightPRs) +
-		(stats.TotalIssues * WeightIssues) +
-		(stats.TotalReviews * WeightReviews) +
-		(stats.TotalStarsEarned * WeightStarsEarned) +
-		(stats.Followers * WeightFollowers)
+	metrics := scoredMetrics(stats)
+	totalScore := Score(stats)
 
 	// Determine rank tier and next rank
 	var rank, nextRank string
@@ -116,5 +188,60 @@ func CalculateRank(stats UserProfileStats) RankInfo {
 		NextRank:          nextRank,
 		NextRankThreshold: nextThreshold,
 		ProgressPercent:   progressPercent,
+		Breakdown:         buildBreakdown(metrics, totalScore),
+		Suggestions:       buildSuggestions(metrics, totalScore, nextRank, nextThreshold),
+	}
+}
+
+// buildBreakdown calculates each metric's points and share of the total score
+func buildBreakdown(metrics []scoredMetric, totalScore int) []MetricBreakdown {
+	breakdown := make([]MetricBreakdown, 0, len(metrics))
+	for _, m := range metrics {
+		points := m.value * m.weight
+
+		var share float64
+		if totalScore > 0 {
+			share = roundOneDecimal(float64(points) * 100 / float64(totalScore))
+		}
+
+		breakdown = append(breakdown, MetricBreakdown{
+			Metric:       m.name,
+			Value:        m.value,
+			Weight:       m.weight,
+			Points:       points,
+			SharePercent: share,
+		})
+	}
+	return breakdown
+}
+
+// buildSuggestions calculates, for each metric, how much more of it alone
+// would be needed to reach the next rank threshold
+func buildSuggestions(metrics []scoredMetric, totalScore int, nextRank string, nextThreshold int) []RankSuggestion {
+	if nextThreshold <= 0 {
+		return nil
+	}
+
+	gap := nextThreshold - totalScore
+	if gap <= 0 {
+		return nil
+	}
+
+	suggestions := make([]RankSuggestion, 0, len(metrics))
+	for _, m := range metrics {
+		// Round up so the suggested amount always closes the gap
+		needed := (gap + m.weight - 1) / m.weight
+		suggestions = append(suggestions, RankSuggestion{
+			Metric:       m.name,
+			AmountNeeded: needed,
+			Message:      fmt.Sprintf("%d more %s to reach %s", needed, m.label, nextRank),
+		})
 	}
+
+	// Cheapest path first
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].AmountNeeded < suggestions[j].AmountNeeded
+	})
+
+	return suggestions
 }
@@ -0,0 +1,307 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// Retry settings for rate-limited and transient failures
+const (
+	maxRetries = 3
+	baseDelay  = time.Second
+	// maxRetryWait is the longest Retry-After we wait out before giving up
+	maxRetryWait = 30 * time.Second
+	// minRemainingBudget stops querying a token before it is fully exhausted,
+	// since a full profile fetch costs several points
+	minRemainingBudget = 10
+)
+
+// RateLimit is the GitHub API budget of a token after the last query
+type RateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Cost      int       `json:"cost"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// rateLimitInfo is the GraphQL structure for a query's rate limit
+type rateLimitInfo struct {
+	Limit     githubv4.Int
+	Remaining githubv4.Int
+	Cost      githubv4.Int
+	ResetAt   githubv4.DateTime
+}
+
+// toRateLimit converts the GraphQL structure, returning nil when the server
+// didn't report a rate limit
+func (r rateLimitInfo) toRateLimit() *RateLimit {
+	if r.Limit == 0 {
+		return nil
+	}
+	return &RateLimit{
+		Limit:     int(r.Limit),
+		Remaining: int(r.Remaining),
+		Cost:      int(r.Cost),
+		ResetAt:   r.ResetAt.Time,
+	}
+}
+
+// RateLimitError is returned when GitHub's primary or secondary rate limit
+// has been hit, or the token's remaining budget is too low to continue
+type RateLimitError struct {
+	ResetAt   time.Time
+	Secondary bool
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "rate limit"
+	if e.Secondary {
+		kind = "secondary rate limit"
+	}
+	if e.ResetAt.IsZero() {
+		return "GitHub " + kind + " exceeded"
+	}
+	return fmt.Sprintf("GitHub %s exceeded, resets at %s", kind, e.ResetAt.Format(time.RFC3339))
+}
+
+// UpstreamError is returned when the forge fails or returns an unexpected response
+type UpstreamError struct {
+	StatusCode int // Zero for GraphQL errors
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+// ErrTimeout is returned when GitHub does not respond in time
+var ErrTimeout = errors.New("GitHub API timed out")
+
+// ErrUserNotFound is returned when no GitHub user has the requested login
+var ErrUserNotFound = errors.New("GitHub user not found")
+
+// budgetTracker remembers the last known rate limit of each token per endpoint
+type budgetTracker struct {
+	mu      sync.Mutex
+	budgets map[string]RateLimit
+}
+
+// budgetKey identifies a token on an endpoint without keeping the token itself
+func budgetKey(endpoint, token string) string {
+	sum := sha256.Sum256([]byte(endpoint + "\x00" + token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// record stores the rate limit reported by a query
+func (b *budgetTracker) record(key string, limit *RateLimit) {
+	if limit == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.budgets == nil {
+		b.budgets = make(map[string]RateLimit)
+	}
+	b.budgets[key] = *limit
+}
+
+// exhaust marks a token as out of budget until resetAt
+func (b *budgetTracker) exhaust(key string, resetAt time.Time) {
+	b.record(key, &RateLimit{Limit: 1, Remaining: 0, ResetAt: resetAt})
+}
+
+// check returns a RateLimitError if the token's known budget is too low
+func (b *budgetTracker) check(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	limit, ok := b.budgets[key]
+	if !ok || time.Now().After(limit.ResetAt) {
+		return nil
+	}
+	if limit.Remaining < minRemainingBudget {
+		return &RateLimitError{ResetAt: limit.ResetAt}
+	}
+	return nil
+}
+
+// get returns the token's last known rate limit
+func (b *budgetTracker) get(key string) *RateLimit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if limit, ok := b.budgets[key]; ok {
+		return &limit
+	}
+	return nil
+}
+
+// responseInfo captures the status and rate limit headers of the last
+// response, which the GraphQL client doesn't expose
+type responseInfo struct {
+	mu         sync.Mutex
+	status     int
+	remaining  string
+	reset      string
+	retryAfter string
+}
+
+// capturingTransport records response details into a responseInfo
+type capturingTransport struct {
+	base http.RoundTripper
+	info *responseInfo
+}
+
+func (t *capturingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.info.mu.Lock()
+	t.info.status = resp.StatusCode
+	t.info.remaining = resp.Header.Get("X-RateLimit-Remaining")
+	t.info.reset = resp.Header.Get("X-RateLimit-Reset")
+	t.info.retryAfter = resp.Header.Get("Retry-After")
+	t.info.mu.Unlock()
+
+	return resp, nil
+}
+
+// session runs the queries of a single profile fetch for one token,
+// tracking its budget and retrying transient failures
+type session struct {
+	client  *githubv4.Client
+	info    *responseInfo
+	budgets *budgetTracker
+	key     string
+}
+
+// query executes a GraphQL query, retrying secondary rate limits and
+// transient errors with exponential backoff and jitter. A retry that can't
+// finish before the context's deadline returns the error right away, so the
+// caller still has time to fall back.
+func (s *session) query(ctx context.Context, q interface{}, variables map[string]interface{}, limit func() rateLimitInfo) error {
+	if err := s.budgets.check(s.key); err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := s.client.Query(ctx, q, variables)
+		if err == nil {
+			s.budgets.record(s.key, limit().toRateLimit())
+			return nil
+		}
+
+		err, wait, retry := s.classify(ctx, err)
+		if !retry || attempt >= maxRetries {
+			return err
+		}
+
+		// Exponential backoff with up to 50% jitter, at least the server's Retry-After
+		delay := baseDelay << attempt
+		delay += time.Duration(rand.Int63n(int64(delay) / 2))
+		if wait > delay {
+			delay = wait
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ErrTimeout
+		case <-time.After(delay):
+		}
+	}
+}
+
+// classify converts a query error into a typed error, reporting whether it is
+// worth retrying and how long the server asked us to wait
+func (s *session) classify(ctx context.Context, err error) (error, time.Duration, bool) {
+	s.info.mu.Lock()
+	status, remaining, reset, retryAfter := s.info.status, s.info.remaining, s.info.reset, s.info.retryAfter
+	s.info.mu.Unlock()
+
+	// Timeouts
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrTimeout, 0, false
+	}
+
+	message := strings.ToLower(err.Error())
+	if strings.Contains(message, "could not resolve to a user") {
+		return ErrUserNotFound, 0, false
+	}
+
+	rateLimited := status == http.StatusTooManyRequests ||
+		(status == http.StatusForbidden && (remaining == "0" || retryAfter != "" || strings.Contains(message, "rate limit"))) ||
+		strings.Contains(message, "rate limit")
+
+	if rateLimited {
+		resetAt := parseReset(reset)
+		secondary := remaining != "0" && (retryAfter != "" || strings.Contains(message, "secondary"))
+
+		// Primary limit exhausted: nothing to do until it resets
+		if !secondary {
+			if resetAt.IsZero() {
+				resetAt = time.Now().Add(time.Minute)
+			}
+			s.budgets.exhaust(s.key, resetAt)
+			return &RateLimitError{ResetAt: resetAt}, 0, false
+		}
+
+		// Secondary limits are short; wait them out if the server says so
+		wait := parseRetryAfter(retryAfter)
+		if wait > maxRetryWait {
+			return &RateLimitError{ResetAt: time.Now().Add(wait), Secondary: true}, 0, false
+		}
+		if resetAt.IsZero() {
+			resetAt = time.Now().Add(wait)
+		}
+		return &RateLimitError{ResetAt: resetAt, Secondary: true}, wait, true
+	}
+
+	// Server errors are usually transient
+	if status >= http.StatusInternalServerError {
+		return &UpstreamError{StatusCode: status, Err: err}, 0, true
+	}
+
+	if status != 0 && status != http.StatusOK {
+		return &UpstreamError{StatusCode: status, Err: err}, 0, false
+	}
+	return &UpstreamError{Err: err}, 0, false
+}
+
+// parseReset parses an X-RateLimit-Reset header (Unix seconds)
+func parseReset(value string) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// parseRetryAfter parses a Retry-After header in seconds
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
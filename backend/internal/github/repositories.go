@@ -0,0 +1,293 @@
+package github
+
+import (
+	"context"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// DefaultMaxRepositoryPages bounds repository pagination at 100 repositories
+// per page, keeping prolific users from exhausting the rate limit
+const DefaultMaxRepositoryPages = 10
+
+// RecentActivityDays is the window for a repository's recent commit count
+const RecentActivityDays = 30
+
+// RepositoryFetcher lists a user's own public repositories with details
+type RepositoryFetcher interface {
+	FetchRepositories(ctx context.Context, username, token string, opts FetchOptions) ([]Repository, bool, error)
+}
+
+// pageInfo is the GraphQL cursor information for a connection
+type pageInfo struct {
+	HasNextPage githubv4.Boolean
+	EndCursor   githubv4.String
+}
+
+// ownedRepositoryPage is a page of the user's own public repositories
+type ownedRepositoryPage struct {
+	TotalCount githubv4.Int
+	PageInfo   pageInfo
+	Nodes      []struct {
+		StargazerCount githubv4.Int `graphql:"stargazerCount"`
+		IsFork         githubv4.Boolean
+		Languages      struct {
+			Edges []struct {
+				Size githubv4.Int
+				Node struct {
+					Name  githubv4.String
+					Color githubv4.String
+				}
+			}
+		} `graphql:"languages(first: 10, orderBy: {field: SIZE, direction: DESC})"`
+	}
+}
+
+// repositoryDetails is the GraphQL structure for a repository's details
+type repositoryDetails struct {
+	Name            githubv4.String
+	Description     githubv4.String
+	URL             githubv4.String `graphql:"url"`
+	StargazerCount  githubv4.Int    `graphql:"stargazerCount"`
+	ForkCount       githubv4.Int
+	IsFork          githubv4.Boolean
+	IsArchived      githubv4.Boolean
+	PushedAt        githubv4.DateTime
+	PrimaryLanguage struct {
+		Name  githubv4.String
+		Color githubv4.String
+	}
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name githubv4.String
+			}
+		}
+	} `graphql:"repositoryTopics(first: 10)"`
+	LicenseInfo struct {
+		SpdxID githubv4.String `graphql:"spdxId"`
+		Name   githubv4.String
+	}
+	Issues struct {
+		TotalCount githubv4.Int
+	} `graphql:"issues(states: OPEN)"`
+	PullRequests struct {
+		TotalCount githubv4.Int
+	} `graphql:"pullRequests(states: OPEN)"`
+	Releases struct {
+		TotalCount githubv4.Int
+	}
+	DefaultBranchRef struct {
+		Target struct {
+			Commit struct {
+				History struct {
+					TotalCount githubv4.Int
+				} `graphql:"history(since: $since)"`
+			} `graphql:"... on Commit"`
+		}
+	}
+}
+
+// toRepository converts the GraphQL structure
+func (r repositoryDetails) toRepository() Repository {
+	topics := make([]string, 0, len(r.RepositoryTopics.Nodes))
+	for _, node := range r.RepositoryTopics.Nodes {
+		topics = append(topics, string(node.Topic.Name))
+	}
+
+	// Custom licenses have no SPDX ID
+	license := string(r.LicenseInfo.SpdxID)
+	if license == "" || license == "NOASSERTION" {
+		license = string(r.LicenseInfo.Name)
+	}
+
+	var pushedAt *time.Time
+	if !r.PushedAt.IsZero() {
+		t := r.PushedAt.Time
+		pushedAt = &t
+	}
+
+	return Repository{
+		Name:           string(r.Name),
+		Description:    string(r.Description),
+		StargazerCount: int(r.StargazerCount),
+		ForkCount:      int(r.ForkCount),
+		URL:            string(r.URL),
+		PrimaryLanguage: Language{
+			Name:  string(r.PrimaryLanguage.Name),
+			Color: string(r.PrimaryLanguage.Color),
+		},
+		Topics:           topics,
+		License:          license,
+		PushedAt:         pushedAt,
+		OpenIssues:       int(r.Issues.TotalCount),
+		OpenPullRequests: int(r.PullRequests.TotalCount),
+		Releases:         int(r.Releases.TotalCount),
+		RecentCommits:    int(r.DefaultBranchRef.Target.Commit.History.TotalCount),
+		IsFork:           bool(r.IsFork),
+		IsArchived:       bool(r.IsArchived),
+	}
+}
+
+// recentActivitySince returns the start of the recent commit activity window
+func recentActivitySince() githubv4.GitTimestamp {
+	return githubv4.GitTimestamp{Time: time.Now().UTC().AddDate(0, 0, -RecentActivityDays)}
+}
+
+// RepositoryListQuery fetches a page of the user's own public repositories with details
+type RepositoryListQuery struct {
+	RateLimit rateLimitInfo
+	User      struct {
+		Repositories struct {
+			PageInfo pageInfo
+			Nodes    []repositoryDetails
+		} `graphql:"repositories(first: 50, after: $cursor, orderBy: {field: PUSHED_AT, direction: DESC}, ownerAffiliations: OWNER, privacy: PUBLIC)"`
+	} `graphql:"user(login: $username)"`
+}
+
+// FetchRepositories lists the user's own public repositories with details,
+// most recently pushed first. The list is truncated after the repository
+// page budget.
+func (c *Client) FetchRepositories(ctx context.Context, username, token string, opts FetchOptions) ([]Repository, bool, error) {
+	endpoint := c.Endpoint
+	if opts.Host != "" && opts.Host != DefaultHost {
+		endpoint = EndpointForHost(opts.Host)
+	}
+	client := c.newSession(ctx, endpoint, token)
+
+	repos := make([]Repository, 0)
+	cursor := (*githubv4.String)(nil)
+	for pages := 1; ; pages++ {
+		var query RepositoryListQuery
+		err := client.query(ctx, &query, map[string]interface{}{
+			"username": githubv4.String(username),
+			"cursor":   cursor,
+			"since":    recentActivitySince(),
+		}, func() rateLimitInfo { return query.RateLimit })
+		if err != nil {
+			return nil, false, err
+		}
+
+		page := query.User.Repositories
+		for _, node := range page.Nodes {
+			repos = append(repos, node.toRepository())
+		}
+
+		if !bool(page.PageInfo.HasNextPage) {
+			return repos, false, nil
+		}
+		if pages >= opts.RepositoryPageBudget() {
+			return repos, true, nil
+		}
+		cursor = githubv4.NewString(page.PageInfo.EndCursor)
+	}
+}
+
+// OwnedRepositoriesQuery fetches subsequent pages of the user's own public repositories
+type OwnedRepositoriesQuery struct {
+	RateLimit rateLimitInfo
+	User      struct {
+		Repositories ownedRepositoryPage `graphql:"repositories(first: 100, after: $cursor, orderBy: {field: STARGAZERS, direction: DESC}, ownerAffiliations: OWNER, privacy: PUBLIC)"`
+	} `graphql:"user(login: $username)"`
+}
+
+// ContributedRepositoriesQuery fetches a page of other public repositories the
+// user has committed to or opened pull requests against
+type ContributedRepositoriesQuery struct {
+	RateLimit rateLimitInfo
+	User      struct {
+		RepositoriesContributedTo struct {
+			PageInfo pageInfo
+			Nodes    []struct {
+				StargazerCount githubv4.Int `graphql:"stargazerCount"`
+				Owner          struct {
+					Typename githubv4.String `graphql:"__typename"`
+				}
+			}
+		} `graphql:"repositoriesContributedTo(first: 100, after: $cursor, includeUserRepositories: false, contributionTypes: [COMMIT, PULL_REQUEST], orderBy: {field: STARGAZERS, direction: DESC}, privacy: PUBLIC)"`
+	} `graphql:"user(login: $username)"`
+}
+
+// ownedRepositoryTotals holds what is collected across the user's own repositories
+type ownedRepositoryTotals struct {
+	stars     int
+	languages *LanguageAggregator
+	truncated bool // The page budget ran out before the last page
+}
+
+// collectOwnedRepositories sums stars and language sizes across all of the
+// user's own public repositories, starting from the first page already
+// fetched with the profile
+func collectOwnedRepositories(ctx context.Context, client *session, username string, page ownedRepositoryPage, maxPages int, ignoredLanguages []string) (ownedRepositoryTotals, error) {
+	totals := ownedRepositoryTotals{languages: NewLanguageAggregator(ignoredLanguages)}
+
+	for pages := 1; ; pages++ {
+		for _, repo := range page.Nodes {
+			totals.stars += int(repo.StargazerCount)
+
+			// Forks mostly contain someone else's code
+			if repo.IsFork {
+				continue
+			}
+			for _, edge := range repo.Languages.Edges {
+				totals.languages.Add(string(edge.Node.Name), string(edge.Node.Color), int(edge.Size))
+			}
+			totals.languages.FinishRepository()
+		}
+
+		if !bool(page.PageInfo.HasNextPage) {
+			return totals, nil
+		}
+		if pages >= maxPages {
+			totals.truncated = true
+			return totals, nil
+		}
+
+		var query OwnedRepositoriesQuery
+		err := client.query(ctx, &query, map[string]interface{}{
+			"username": githubv4.String(username),
+			"cursor":   githubv4.NewString(page.PageInfo.EndCursor),
+		}, func() rateLimitInfo { return query.RateLimit })
+		if err != nil {
+			return totals, err
+		}
+		page = query.User.Repositories
+	}
+}
+
+// countContributedStars sums stars across organization-owned public
+// repositories the user has contributed to. The returned flag reports whether
+// the page budget ran out first.
+func countContributedStars(ctx context.Context, client *session, username string, maxPages int) (int, bool, error) {
+	total := 0
+	var cursor *githubv4.String
+
+	for pages := 0; pages < maxPages; pages++ {
+		var query ContributedRepositoriesQuery
+		err := client.query(ctx, &query, map[string]interface{}{
+			"username": githubv4.String(username),
+			"cursor":   cursor,
+		}, func() rateLimitInfo { return query.RateLimit })
+		if err != nil {
+			return 0, false, err
+		}
+
+		repos := query.User.RepositoriesContributedTo
+		for _, repo := range repos.Nodes {
+			if repo.StargazerCount == 0 {
+				return total, false, nil
+			}
+			if repo.Owner.Typename == "Organization" {
+				total += int(repo.StargazerCount)
+			}
+		}
+
+		if !bool(repos.PageInfo.HasNextPage) {
+			return total, false, nil
+		}
+		cursor = githubv4.NewString(repos.PageInfo.EndCursor)
+	}
+
+	return total, true, nil
+}
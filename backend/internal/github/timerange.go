@@ -0,0 +1,132 @@
+package github
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Time window limits
+const (
+	// MaxRangeYears bounds multi-year ranges to limit the number of chunked queries
+	MaxRangeYears = 10
+	// earliestYear is the first year GitHub has contribution data for
+	earliestYear = 2008
+)
+
+// Rolling windows supported by ParseTimeRange, in days
+var rollingWindows = map[string]int{
+	"30d":  30,
+	"90d":  90,
+	"365d": 365,
+}
+
+// TimeRange is the period contribution statistics are collected over
+type TimeRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// DefaultTimeRange returns the range from the start of the current year to now
+func DefaultTimeRange(now time.Time) TimeRange {
+	now = now.UTC()
+	return TimeRange{
+		From: time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   now,
+	}
+}
+
+// ParseTimeRange builds a time range from query parameters. Exactly one of
+// window (30d, 90d or 365d), year, or from_year/to_year may be set; when none
+// are set the default range is returned.
+func ParseTimeRange(window, year, fromYear, toYear string, now time.Time) (TimeRange, error) {
+	now = now.UTC()
+
+	set := 0
+	for _, v := range []string{window, year, fromYear + toYear} {
+		if v != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return TimeRange{}, errors.New("use only one of window, year, or from_year/to_year")
+	}
+
+	switch {
+	case window != "":
+		days, ok := rollingWindows[window]
+		if !ok {
+			return TimeRange{}, errors.New("window must be one of 30d, 90d or 365d")
+		}
+		return TimeRange{From: now.AddDate(0, 0, -days), To: now}, nil
+
+	case year != "":
+		y, err := parseYear(year, now)
+		if err != nil {
+			return TimeRange{}, err
+		}
+		return yearRange(y, y, now), nil
+
+	case fromYear != "" || toYear != "":
+		from, to := now.Year(), now.Year()
+		var err error
+		if fromYear != "" {
+			if from, err = parseYear(fromYear, now); err != nil {
+				return TimeRange{}, err
+			}
+		}
+		if toYear != "" {
+			if to, err = parseYear(toYear, now); err != nil {
+				return TimeRange{}, err
+			}
+		}
+		if from > to {
+			return TimeRange{}, errors.New("from_year must not be after to_year")
+		}
+		if to-from+1 > MaxRangeYears {
+			return TimeRange{}, errors.New("year ranges are limited to " + strconv.Itoa(MaxRangeYears) + " years")
+		}
+		return yearRange(from, to, now), nil
+	}
+
+	return DefaultTimeRange(now), nil
+}
+
+// Chunks splits the range into consecutive pieces of at most one year, in
+// chronological order
+func (r TimeRange) Chunks() []TimeRange {
+	var chunks []TimeRange
+	from := r.From
+	for {
+		next := from.AddDate(1, 0, 0)
+		if !next.Before(r.To) {
+			chunks = append(chunks, TimeRange{From: from, To: r.To})
+			return chunks
+		}
+		// End one second early so boundary days aren't counted twice
+		chunks = append(chunks, TimeRange{From: from, To: next.Add(-time.Second)})
+		from = next
+	}
+}
+
+// yearRange returns the range covering whole calendar years, ending now if
+// the last year is the current one
+func yearRange(from, to int, now time.Time) TimeRange {
+	end := time.Date(to, 12, 31, 23, 59, 59, 0, time.UTC)
+	if end.After(now) {
+		end = now
+	}
+	return TimeRange{
+		From: time.Date(from, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   end,
+	}
+}
+
+// parseYear parses a calendar year GitHub has contribution data for
+func parseYear(value string, now time.Time) (int, error) {
+	y, err := strconv.Atoi(value)
+	if err != nil || y < earliestYear || y > now.Year() {
+		return 0, errors.New("year must be between " + strconv.Itoa(earliestYear) + " and " + strconv.Itoa(now.Year()))
+	}
+	return y, nil
+}
@@ -0,0 +1,354 @@
+// Package gitlab fetches developer statistics from GitLab's REST API and maps
+// them onto the same metrics used for GitHub profiles.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+)
+
+// DefaultHost is the host of the public GitLab instance
+const DefaultHost = "gitlab.com"
+
+// pageSize is the number of items requested per page
+const pageSize = 100
+
+// maxProjectLookups bounds how many projects outside the bulk listings have
+// their visibility looked up one by one; the rest are treated as private
+const maxProjectLookups = 10
+
+// maxPinned is the number of most-starred projects returned as pinned repositories
+const maxPinned = 6
+
+// ErrUserNotFound is returned when no GitLab user has the requested username
+var ErrUserNotFound = errors.New("GitLab user not found")
+
+// Client fetches profiles from GitLab instances
+type Client struct {
+	// BaseURL overrides the instance URL derived from the account host (e.g. for a local stub server)
+	BaseURL string
+	// HTTPClient is the client requests are made with; nil uses http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// NewClient creates a GitLab client
+func NewClient(httpClient *http.Client) *Client {
+	return &Client{HTTPClient: httpClient}
+}
+
+// user is a GitLab user as returned by the users API
+type user struct {
+	ID        int    `json:"id"`
+	Username  string `json:"username"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+	Bio       string `json:"bio"`
+	Followers int    `json:"followers"`
+}
+
+// event is a GitLab contribution event
+type event struct {
+	ProjectID  int    `json:"project_id"`
+	ActionName string `json:"action_name"`
+	TargetType string `json:"target_type"`
+	CreatedAt  string `json:"created_at"`
+	PushData   *struct {
+		CommitCount int `json:"commit_count"`
+	} `json:"push_data"`
+	Note *struct {
+		NoteableType string `json:"noteable_type"`
+	} `json:"note"`
+}
+
+// project is a GitLab project owned by the user
+type project struct {
+	ID                int             `json:"id"`
+	Name              string          `json:"name"`
+	Description       string          `json:"description"`
+	WebURL            string          `json:"web_url"`
+	StarCount         int             `json:"star_count"`
+	ForksCount        int             `json:"forks_count"`
+	ForkedFromProject json.RawMessage `json:"forked_from_project"`
+}
+
+// FetchUserProfile fetches a GitLab user's profile and maps commits, merge
+// requests, issues, reviews and stars onto GitHub profile statistics.
+// Merge request approvals and comments count as reviews. Events in projects
+// that aren't public are only counted with opts.IncludePrivate.
+func (c *Client) FetchUserProfile(ctx context.Context, username, token string, opts github.FetchOptions) (*github.UserProfileStats, error) {
+	base := c.baseURL(opts.Host)
+	period := opts.PeriodOrDefault()
+
+	// Look up the user ID by username
+	var matches []user
+	if err := c.get(ctx, base+"/api/v4/users?username="+url.QueryEscape(username), token, &matches); err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	var profile user
+	if err := c.get(ctx, fmt.Sprintf("%s/api/v4/users/%d", base, matches[0].ID), token, &profile); err != nil {
+		return nil, err
+	}
+
+	stats := &github.UserProfileStats{
+		Login:     profile.Username,
+		Name:      profile.Name,
+		AvatarURL: profile.AvatarURL,
+		Bio:       profile.Bio,
+		Followers: profile.Followers,
+		Period:    period,
+	}
+
+	// Map contribution events within the period. GitLab's after/before
+	// filters are exclusive dates.
+	dayCounts := make(map[string]int)
+	after := period.From.AddDate(0, 0, -1).Format("2006-01-02")
+	before := period.To.AddDate(0, 0, 1).Format("2006-01-02")
+	maxPages := opts.RepositoryPageBudget()
+
+	var events []event
+	for page := 1; page <= maxPages; page++ {
+		var batch []event
+		eventsURL := fmt.Sprintf("%s/api/v4/users/%d/events?after=%s&before=%s&per_page=%d&page=%d", base, profile.ID, after, before, pageSize, page)
+		if err := c.get(ctx, eventsURL, token, &batch); err != nil {
+			return nil, err
+		}
+		events = append(events, batch...)
+
+		if len(batch) < pageSize {
+			break
+		}
+	}
+
+	// Sum stars across the user's public projects
+	var projects []project
+	for page := 1; page <= maxPages; page++ {
+		var batch []project
+		projectsURL := fmt.Sprintf("%s/api/v4/users/%d/projects?visibility=public&per_page=%d&page=%d", base, profile.ID, pageSize, page)
+		if err := c.get(ctx, projectsURL, token, &batch); err != nil {
+			return nil, err
+		}
+		projects = append(projects, batch...)
+
+		if len(batch) < pageSize {
+			break
+		}
+		if page == maxPages {
+			stats.StarsTruncated = true
+		}
+	}
+
+	publicProjects, err := c.projectVisibility(ctx, base, token, events, projects, maxPages)
+	if err != nil {
+		return nil, err
+	}
+
+	private := github.PrivateContributions{Days: make(map[string]int)}
+	var privateStats github.UserProfileStats
+	for _, e := range events {
+		// Events without a project, such as joining a group, are public
+		public := e.ProjectID == 0 || publicProjects[e.ProjectID]
+		if !public && !opts.IncludePrivate {
+			continue
+		}
+
+		contributions := mapEvent(e, stats)
+		if contributions == 0 || len(e.CreatedAt) < 10 {
+			continue
+		}
+		dayCounts[e.CreatedAt[:10]] += contributions
+		if !public {
+			mapEvent(e, &privateStats)
+			private.Days[e.CreatedAt[:10]] += contributions
+		}
+	}
+	stats.ContributionCalendar = github.CalendarFromCounts(dayCounts)
+
+	if opts.IncludePrivate {
+		private.Commits = privateStats.TotalCommits
+		private.PullRequests = privateStats.TotalPullRequests
+		private.Issues = privateStats.TotalIssues
+		private.Reviews = privateStats.TotalReviews
+		stats.IncludesPrivate = true
+		stats.Private = &private
+	}
+
+	for _, p := range projects {
+		stats.TotalStarsEarned += p.StarCount
+	}
+	stats.TotalPublicRepositories = len(projects)
+	stats.PinnedRepositories = pinnedProjects(projects)
+	stats.TopLanguages = []github.LanguageStat{}
+
+	return stats, nil
+}
+
+// projectVisibility reports which projects of the events are public. The
+// user's public projects and the public projects the token's owner belongs to
+// are listed in bulk; up to maxProjectLookups others are looked up one by
+// one, and the rest, like projects the token can't see, count as private.
+func (c *Client) projectVisibility(ctx context.Context, base, token string, events []event, owned []project, maxPages int) (map[int]bool, error) {
+	public := make(map[int]bool)
+	for _, p := range owned {
+		public[p.ID] = true
+	}
+
+	unknown := func() []int {
+		seen := make(map[int]bool)
+		var ids []int
+		for _, e := range events {
+			if e.ProjectID != 0 && !public[e.ProjectID] && !seen[e.ProjectID] {
+				seen[e.ProjectID] = true
+				ids = append(ids, e.ProjectID)
+			}
+		}
+		return ids
+	}
+	if len(unknown()) == 0 {
+		return public, nil
+	}
+
+	for page := 1; page <= maxPages; page++ {
+		var batch []project
+		projectsURL := fmt.Sprintf("%s/api/v4/projects?membership=true&visibility=public&simple=true&per_page=%d&page=%d", base, pageSize, page)
+		if err := c.get(ctx, projectsURL, token, &batch); err != nil {
+			return nil, err
+		}
+		for _, p := range batch {
+			public[p.ID] = true
+		}
+
+		if len(batch) < pageSize {
+			break
+		}
+	}
+
+	remaining := unknown()
+	if len(remaining) > maxProjectLookups {
+		remaining = remaining[:maxProjectLookups]
+	}
+	for _, id := range remaining {
+		var p struct {
+			Visibility string `json:"visibility"`
+		}
+		err := c.get(ctx, fmt.Sprintf("%s/api/v4/projects/%d", base, id), token, &p)
+		if err != nil && !errors.Is(err, ErrUserNotFound) {
+			return nil, err
+		}
+		public[id] = p.Visibility == "public"
+	}
+	return public, nil
+}
+
+// mapEvent adds a contribution event to the stats and returns how many
+// contributions it represents on the calendar
+func mapEvent(e event, stats *github.UserProfileStats) int {
+	switch {
+	case strings.HasPrefix(e.ActionName, "pushed") && e.PushData != nil:
+		stats.TotalCommits += e.PushData.CommitCount
+		return e.PushData.CommitCount
+	case e.ActionName == "opened" && e.TargetType == "MergeRequest":
+		stats.TotalPullRequests++
+		return 1
+	case e.ActionName == "opened" && e.TargetType == "Issue":
+		stats.TotalIssues++
+		return 1
+	case e.ActionName == "approved":
+		stats.TotalReviews++
+		return 1
+	case e.ActionName == "commented on" && e.Note != nil && e.Note.NoteableType == "MergeRequest":
+		stats.TotalReviews++
+		return 1
+	}
+	return 0
+}
+
+// pinnedProjects returns the user's most starred original projects
+func pinnedProjects(projects []project) []github.Repository {
+	originals := make([]project, 0, len(projects))
+	for _, p := range projects {
+		if len(p.ForkedFromProject) == 0 || string(p.ForkedFromProject) == "null" {
+			originals = append(originals, p)
+		}
+	}
+
+	sort.SliceStable(originals, func(i, j int) bool {
+		return originals[i].StarCount > originals[j].StarCount
+	})
+	if len(originals) > maxPinned {
+		originals = originals[:maxPinned]
+	}
+
+	pinned := make([]github.Repository, 0, len(originals))
+	for _, p := range originals {
+		pinned = append(pinned, github.Repository{
+			Name:           p.Name,
+			Description:    p.Description,
+			StargazerCount: p.StarCount,
+			ForkCount:      p.ForksCount,
+			URL:            p.WebURL,
+		})
+	}
+	return pinned
+}
+
+// baseURL returns the instance URL for a host
+func (c *Client) baseURL(host string) string {
+	if c.BaseURL != "" {
+		return strings.TrimSuffix(c.BaseURL, "/")
+	}
+	if host == "" {
+		host = DefaultHost
+	}
+	return "https://" + host
+}
+
+// get performs an authenticated GET request and decodes the JSON response
+func (c *Client) get(ctx context.Context, url, token string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", token)
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrUserNotFound
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return &github.RateLimitError{ResetAt: time.Now().Add(time.Duration(retryAfter) * time.Second)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &github.UpstreamError{
+			StatusCode: resp.StatusCode,
+			Err:        errors.New("GitLab API returned status " + strconv.Itoa(resp.StatusCode)),
+		}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
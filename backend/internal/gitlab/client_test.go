@@ -0,0 +1,268 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+)
+
+// testToken is the token the stub server accepts
+const testToken = "test-token"
+
+// testPeriod covers the stub server's events
+var testPeriod = github.TimeRange{
+	From: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	To:   time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC),
+}
+
+// newStubServer serves a GitLab user "alice" with ID 7
+func newStubServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("username") != "alice" {
+			respondJSON(w, []user{})
+			return
+		}
+		respondJSON(w, []user{{ID: 7, Username: "alice"}})
+	})
+	mux.HandleFunc("GET /api/v4/users/7", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, user{ID: 7, Username: "alice", Name: "Alice", AvatarURL: "https://gitlab.example/a.png", Bio: "Hi", Followers: 5})
+	})
+	mux.HandleFunc("GET /api/v4/users/7/events", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("after") != "2024-12-31" || r.URL.Query().Get("before") != "2025-02-01" {
+			t.Errorf("events range = %s..%s", r.URL.Query().Get("after"), r.URL.Query().Get("before"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"project_id": 1, "action_name": "pushed to", "created_at": "2025-01-02T10:00:00Z", "push_data": {"commit_count": 3}},
+			{"project_id": 1, "action_name": "pushed new", "created_at": "2025-01-02T12:00:00Z", "push_data": {"commit_count": 1}},
+			{"project_id": 1, "action_name": "opened", "target_type": "MergeRequest", "created_at": "2025-01-03T09:00:00Z"},
+			{"project_id": 1, "action_name": "opened", "target_type": "Issue", "created_at": "2025-01-04T09:00:00Z"},
+			{"project_id": 1, "action_name": "approved", "target_type": "MergeRequest", "created_at": "2025-01-05T09:00:00Z"},
+			{"project_id": 1, "action_name": "commented on", "target_type": "Note", "created_at": "2025-01-05T10:00:00Z", "note": {"noteable_type": "MergeRequest"}},
+			{"project_id": 1, "action_name": "commented on", "target_type": "Note", "created_at": "2025-01-05T11:00:00Z", "note": {"noteable_type": "Issue"}},
+			{"action_name": "joined", "created_at": "2025-01-06T09:00:00Z"},
+			{"project_id": 2, "action_name": "pushed to", "created_at": "2025-01-07T09:00:00Z", "push_data": {"commit_count": 5}},
+			{"project_id": 3, "action_name": "opened", "target_type": "MergeRequest", "created_at": "2025-01-08T09:00:00Z"},
+			{"project_id": 4, "action_name": "approved", "target_type": "MergeRequest", "created_at": "2025-01-09T09:00:00Z"}
+		]`))
+	})
+	// Project 1 is listed among the token owner's public projects, and only
+	// the rest are looked up; project 4 isn't visible to the token
+	mux.HandleFunc("GET /api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("membership") != "true" || q.Get("visibility") != "public" || q.Get("simple") != "true" {
+			t.Errorf("membership projects query = %s", r.URL.RawQuery)
+		}
+		respondJSON(w, []project{{ID: 1}})
+	})
+	for id, visibility := range map[string]string{"2": "private", "3": "internal"} {
+		mux.HandleFunc("GET /api/v4/projects/"+id, func(w http.ResponseWriter, r *http.Request) {
+			respondJSON(w, map[string]string{"visibility": visibility})
+		})
+	}
+	mux.HandleFunc("GET /api/v4/users/7/projects", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("visibility") != "public" {
+			t.Errorf("projects visibility = %q", r.URL.Query().Get("visibility"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[
+			{"id": 10, "name": "small", "web_url": "https://gitlab.example/alice/small", "star_count": 2},
+			{"id": 11, "name": "fork", "star_count": 50, "forked_from_project": {"id": 1}},
+			{"id": 12, "name": "big", "description": "Popular", "web_url": "https://gitlab.example/alice/big", "star_count": 10, "forks_count": 3}
+		]`))
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// respondJSON writes a JSON response
+func respondJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestFetchUserProfile(t *testing.T) {
+	server := newStubServer(t)
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+
+	stats, err := client.FetchUserProfile(context.Background(), "alice", testToken, github.FetchOptions{Period: testPeriod})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+
+	if stats.Login != "alice" || stats.Name != "Alice" || stats.Bio != "Hi" || stats.Followers != 5 {
+		t.Errorf("profile = %+v", stats)
+	}
+	// Approvals and merge request comments count as reviews; issue comments don't
+	if stats.TotalCommits != 4 || stats.TotalPullRequests != 1 || stats.TotalIssues != 1 || stats.TotalReviews != 2 {
+		t.Errorf("totals = %d commits, %d MRs, %d issues, %d reviews",
+			stats.TotalCommits, stats.TotalPullRequests, stats.TotalIssues, stats.TotalReviews)
+	}
+	if stats.ContributionCalendar.TotalContributions != 8 {
+		t.Errorf("calendar total = %d, want 8", stats.ContributionCalendar.TotalContributions)
+	}
+	if stats.TotalStarsEarned != 62 || stats.TotalPublicRepositories != 3 || stats.StarsTruncated {
+		t.Errorf("stars = %d over %d projects (truncated %v)", stats.TotalStarsEarned, stats.TotalPublicRepositories, stats.StarsTruncated)
+	}
+	// Forks aren't pinned, and the rest are ordered by stars
+	if len(stats.PinnedRepositories) != 2 || stats.PinnedRepositories[0].Name != "big" || stats.PinnedRepositories[1].Name != "small" {
+		t.Errorf("pinned = %+v", stats.PinnedRepositories)
+	}
+	if pinned := stats.PinnedRepositories[0]; pinned.Description != "Popular" || pinned.ForkCount != 3 || pinned.URL != "https://gitlab.example/alice/big" {
+		t.Errorf("pinned project = %+v", pinned)
+	}
+}
+
+func TestFetchUserProfileIncludePrivate(t *testing.T) {
+	server := newStubServer(t)
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+
+	public, err := client.FetchUserProfile(context.Background(), "alice", testToken, github.FetchOptions{Period: testPeriod})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+	if public.IncludesPrivate || public.Private != nil {
+		t.Errorf("private contributions reported without consent")
+	}
+
+	stats, err := client.FetchUserProfile(context.Background(), "alice", testToken, github.FetchOptions{Period: testPeriod, IncludePrivate: true})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+	// Private and internal projects, and projects the token can't see, are private
+	if stats.TotalCommits != 9 || stats.TotalPullRequests != 2 || stats.TotalReviews != 3 || stats.ContributionCalendar.TotalContributions != 15 {
+		t.Errorf("totals = %d commits, %d MRs, %d reviews, calendar %d",
+			stats.TotalCommits, stats.TotalPullRequests, stats.TotalReviews, stats.ContributionCalendar.TotalContributions)
+	}
+	private := stats.Private
+	if !stats.IncludesPrivate || private == nil || private.Commits != 5 || private.PullRequests != 1 || private.Reviews != 1 || len(private.Days) != 3 {
+		t.Fatalf("private = %+v", private)
+	}
+
+	// Removing the private part gives the profile fetched without consent
+	without := stats.WithoutPrivate()
+	if without.TotalCommits != public.TotalCommits || without.TotalPullRequests != public.TotalPullRequests ||
+		without.TotalReviews != public.TotalReviews || without.ContributionCalendar.TotalContributions != public.ContributionCalendar.TotalContributions {
+		t.Errorf("without private = %+v, want %+v", without, public)
+	}
+}
+
+func TestFetchUserProfileCapsProjectLookups(t *testing.T) {
+	var lookups int
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/users", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, []user{{ID: 7, Username: "alice"}})
+	})
+	mux.HandleFunc("GET /api/v4/users/7", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, user{ID: 7, Username: "alice"})
+	})
+	mux.HandleFunc("GET /api/v4/users/7/events", func(w http.ResponseWriter, r *http.Request) {
+		events := make([]event, 0, 2*maxProjectLookups)
+		for id := 1; id <= 2*maxProjectLookups; id++ {
+			events = append(events, event{ProjectID: id, ActionName: "opened", TargetType: "Issue", CreatedAt: "2025-01-02T10:00:00Z"})
+		}
+		respondJSON(w, events)
+	})
+	mux.HandleFunc("GET /api/v4/users/7/projects", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, []project{})
+	})
+	mux.HandleFunc("GET /api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, []project{})
+	})
+	mux.HandleFunc("GET /api/v4/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		respondJSON(w, map[string]string{"visibility": "public"})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+	stats, err := client.FetchUserProfile(context.Background(), "alice", testToken, github.FetchOptions{Period: testPeriod})
+	if err != nil {
+		t.Fatalf("FetchUserProfile: %v", err)
+	}
+	// Projects past the cap aren't looked up and count as private
+	if lookups != maxProjectLookups {
+		t.Errorf("looked up %d projects, want %d", lookups, maxProjectLookups)
+	}
+	if stats.TotalIssues != maxProjectLookups {
+		t.Errorf("issues = %d, want %d", stats.TotalIssues, maxProjectLookups)
+	}
+}
+
+func TestFetchUserProfileUnknownUser(t *testing.T) {
+	server := newStubServer(t)
+	client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+
+	_, err := client.FetchUserProfile(context.Background(), "nobody", testToken, github.FetchOptions{Period: testPeriod})
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("err = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestFetchUserProfileErrorStatuses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header map[string]string
+		check  func(t *testing.T, err error)
+	}{
+		{"not found", http.StatusNotFound, nil, func(t *testing.T, err error) {
+			if !errors.Is(err, ErrUserNotFound) {
+				t.Errorf("err = %v, want ErrUserNotFound", err)
+			}
+		}},
+		{"rate limited", http.StatusTooManyRequests, map[string]string{"Retry-After": "60"}, func(t *testing.T, err error) {
+			var limitErr *github.RateLimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("err = %v, want RateLimitError", err)
+			}
+			if wait := time.Until(limitErr.ResetAt); wait < 55*time.Second || wait > 65*time.Second {
+				t.Errorf("reset in %v, want about a minute", wait)
+			}
+		}},
+		{"unauthorized", http.StatusUnauthorized, nil, func(t *testing.T, err error) {
+			var upstreamErr *github.UpstreamError
+			if !errors.As(err, &upstreamErr) || upstreamErr.StatusCode != http.StatusUnauthorized {
+				t.Errorf("err = %v, want 401 UpstreamError", err)
+			}
+		}},
+		{"server error", http.StatusBadGateway, nil, func(t *testing.T, err error) {
+			var upstreamErr *github.UpstreamError
+			if !errors.As(err, &upstreamErr) || upstreamErr.StatusCode != http.StatusBadGateway {
+				t.Errorf("err = %v, want 502 UpstreamError", err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, value := range tt.header {
+					w.Header().Set(key, value)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := &Client{BaseURL: server.URL, HTTPClient: server.Client()}
+			_, err := client.FetchUserProfile(context.Background(), "alice", testToken, github.FetchOptions{Period: testPeriod})
+			tt.check(t, err)
+		})
+	}
+}
@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/retention"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
 	"gorm.io/gorm"
 )
@@ -27,6 +30,11 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// ReauthenticateRequest represents the re-authentication payload
+type ReauthenticateRequest struct {
+	Password string `json:"password"`
+}
+
 // AuthResponse represents the authentication response
 type AuthResponse struct {
 	Token string       `json:"token"`
@@ -135,3 +143,104 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		User:  &user,
 	})
 }
+
+// RestoreAccount restores an account deleted within the grace period and
+// signs the user in. It takes the same payload as Login.
+func (h *AuthHandler) RestoreAccount(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Normalize email
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+
+	if req.Email == "" || req.Password == "" {
+		utils.RespondError(w, http.StatusBadRequest, "Email and password are required")
+		return
+	}
+
+	// Find a deleted account that is still restorable
+	user, err := retention.FindRestorable(h.DB, req.Email)
+	if err != nil {
+		if errors.Is(err, retention.ErrNotRestorable) {
+			utils.RespondError(w, http.StatusUnauthorized, "Invalid email or password")
+			return
+		}
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to restore account")
+		return
+	}
+
+	// Verify password
+	if !utils.VerifyPassword(user.PasswordHash, req.Password) {
+		utils.RespondError(w, http.StatusUnauthorized, "Invalid email or password")
+		return
+	}
+
+	if err := retention.Restore(h.DB, user); err != nil {
+		if errors.Is(err, retention.ErrEmailInUse) {
+			utils.RespondError(w, http.StatusConflict, "Email already registered to another account")
+			return
+		}
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to restore account")
+		return
+	}
+
+	// Generate JWT token
+	token, err := utils.GenerateToken(user.ID)
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	utils.RespondSuccess(w, AuthResponse{
+		Token: token,
+		User:  user,
+	})
+}
+
+// Reauthenticate confirms the signed-in user's password and issues a fresh
+// token, unlocking routes that require recent authentication. Passwords are
+// the only factor this service supports.
+func (h *AuthHandler) Reauthenticate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r)
+	if !ok {
+		utils.RespondError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req ReauthenticateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if req.Password == "" {
+		utils.RespondError(w, http.StatusBadRequest, "Password is required")
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		utils.RespondError(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	// Verify password
+	if !utils.VerifyPassword(user.PasswordHash, req.Password) {
+		utils.RespondError(w, http.StatusUnauthorized, "Invalid password")
+		return
+	}
+
+	// Generate JWT token
+	token, err := utils.GenerateToken(user.ID)
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	utils.RespondSuccess(w, AuthResponse{
+		Token: token,
+		User:  &user,
+	})
+}
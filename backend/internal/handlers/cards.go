@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/cards"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/snapshots"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// Card cache lifetimes in seconds
+const (
+	cardMaxAge        = 1800
+	messageCardMaxAge = 300
+)
+
+// privateContributionsLabel marks cards whose stats count private contributions
+const privateContributionsLabel = " (incl. private)"
+
+// cardSubject is the data available for rendering a user's cards
+type cardSubject struct {
+	user  *models.User
+	stats *github.UserProfileStats
+	rank  github.RankInfo
+}
+
+// loadCardSubject loads the public user and latest snapshot for a card request.
+// The visible callback decides whether the user's privacy settings allow the
+// card. On failure it writes a message card and returns nil.
+func loadCardSubject(w http.ResponseWriter, r *http.Request, db *gorm.DB, theme cards.Theme, visible func(models.PrivacySettings) bool) *cardSubject {
+	user, err := findPublicUser(db, chi.URLParam(r, "handle"))
+	if err != nil || !visible(user.Privacy) {
+		writeMessageCard(w, r, http.StatusNotFound, "Profile not found", theme)
+		return nil
+	}
+
+	snap, err := snapshots.Latest(db, user.ID)
+	if err != nil {
+		writeMessageCard(w, r, http.StatusOK, "No GitHub stats yet", theme)
+		return nil
+	}
+
+	stats, err := snapshots.Decode(snap)
+	if err != nil {
+		log.Printf("Failed to decode GitHub snapshot %d: %v", snap.ID, err)
+		writeMessageCard(w, r, http.StatusOK, "No GitHub stats yet", theme)
+		return nil
+	}
+
+	stats = publicStats(user, stats)
+
+	return &cardSubject{
+		user:  user,
+		stats: stats,
+		rank:  github.CalculateRank(*stats),
+	}
+}
+
+// cardTitle returns the display title for a user's card, labelled when the
+// stats include private contributions the user chose to share
+func cardTitle(subject *cardSubject, suffix string) string {
+	name := subject.stats.Name
+	if name == "" {
+		name = subject.user.Name
+	}
+	if subject.stats.IncludesPrivate {
+		suffix += privateContributionsLabel
+	}
+	return name + suffix
+}
+
+// GetRankCard renders the user's rank tier and progress as an embeddable SVG
+func GetRankCard(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		theme := cards.GetTheme(r.URL.Query().Get("theme"))
+
+		subject := loadCardSubject(w, r, db, theme, func(p models.PrivacySettings) bool { return p.ShowRank })
+		if subject == nil {
+			return
+		}
+
+		svg, err := cards.RenderRankCard(cardTitle(subject, "'s Developer Rank"), subject.rank, theme)
+		if err != nil {
+			http.Error(w, "Failed to render card", http.StatusInternalServerError)
+			return
+		}
+		writeCard(w, r, http.StatusOK, "image/svg+xml", svg, cardMaxAge)
+	}
+}
+
+// GetStatsCard renders the user's key GitHub statistics as an embeddable SVG
+func GetStatsCard(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		theme := cards.GetTheme(r.URL.Query().Get("theme"))
+
+		subject := loadCardSubject(w, r, db, theme, func(p models.PrivacySettings) bool { return p.ShowGithubStats })
+		if subject == nil {
+			return
+		}
+
+		// Only show the rank tier if the user shares it
+		rank := subject.rank
+		if !subject.user.Privacy.ShowRank {
+			rank = github.RankInfo{}
+		}
+
+		svg, err := cards.RenderStatsCard(cardTitle(subject, "'s GitHub Stats"), *subject.stats, rank, theme)
+		if err != nil {
+			http.Error(w, "Failed to render card", http.StatusInternalServerError)
+			return
+		}
+		writeCard(w, r, http.StatusOK, "image/svg+xml", svg, cardMaxAge)
+	}
+}
+
+// GetLanguagesCard renders the user's top languages as an embeddable SVG
+func GetLanguagesCard(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		theme := cards.GetTheme(r.URL.Query().Get("theme"))
+
+		subject := loadCardSubject(w, r, db, theme, func(p models.PrivacySettings) bool { return p.ShowGithubStats })
+		if subject == nil {
+			return
+		}
+
+		if len(subject.stats.TopLanguages) == 0 {
+			writeMessageCard(w, r, http.StatusOK, "No language data yet", theme)
+			return
+		}
+
+		svg, err := cards.RenderLanguagesCard(cardTitle(subject, "'s Top Languages"), subject.stats.TopLanguages, theme)
+		if err != nil {
+			http.Error(w, "Failed to render card", http.StatusInternalServerError)
+			return
+		}
+		writeCard(w, r, http.StatusOK, "image/svg+xml", svg, cardMaxAge)
+	}
+}
+
+// writeMessageCard writes a short-lived SVG card containing a message
+func writeMessageCard(w http.ResponseWriter, r *http.Request, status int, message string, theme cards.Theme) {
+	svg, err := cards.RenderMessageCard(message, theme)
+	if err != nil {
+		http.Error(w, message, status)
+		return
+	}
+	writeCard(w, r, status, "image/svg+xml", svg, messageCardMaxAge)
+}
+
+// writeCard writes a rendered card with cache headers and a content-based
+// ETag, answering conditional requests with 304 Not Modified
+func writeCard(w http.ResponseWriter, r *http.Request, status int, contentType string, body []byte, maxAge int) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+	w.Header().Set("ETag", etag)
+
+	if status == http.StatusOK && etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// etagMatches reports whether an If-None-Match header matches the ETag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetHeatmap renders the user's contribution calendar as an SVG or PNG
+// heatmap. The format is taken from the route's {format} parameter.
+func GetHeatmap(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		theme := cards.GetTheme(query.Get("theme"))
+
+		opts := cards.HeatmapOptions{Scale: query.Get("scale")}
+
+		if size := query.Get("cell_size"); size != "" {
+			n, err := strconv.Atoi(size)
+			if err != nil || n < cards.MinCellSize || n > cards.MaxCellSize {
+				http.Error(w, fmt.Sprintf("cell_size must be between %d and %d", cards.MinCellSize, cards.MaxCellSize), http.StatusBadRequest)
+				return
+			}
+			opts.CellSize = n
+		}
+
+		var err error
+		if opts.From, err = parseDateParam(query.Get("from")); err != nil {
+			http.Error(w, "from must be a date in YYYY-MM-DD format", http.StatusBadRequest)
+			return
+		}
+		if opts.To, err = parseDateParam(query.Get("to")); err != nil {
+			http.Error(w, "to must be a date in YYYY-MM-DD format", http.StatusBadRequest)
+			return
+		}
+		if !opts.From.IsZero() && !opts.To.IsZero() {
+			if opts.From.After(opts.To) {
+				http.Error(w, "from must not be after to", http.StatusBadRequest)
+				return
+			}
+			if opts.To.Sub(opts.From) >= cards.MaxHeatmapDays*24*time.Hour {
+				http.Error(w, fmt.Sprintf("The range can span at most %d days", cards.MaxHeatmapDays), http.StatusBadRequest)
+				return
+			}
+		}
+
+		subject := loadCardSubject(w, r, db, theme, func(p models.PrivacySettings) bool { return p.ShowGithubStats })
+		if subject == nil {
+			return
+		}
+
+		switch chi.URLParam(r, "format") {
+		case "png":
+			img, err := cards.RenderHeatmapPNG(subject.stats.ContributionCalendar, opts)
+			if err != nil {
+				http.Error(w, "Failed to render heatmap", http.StatusInternalServerError)
+				return
+			}
+			writeCard(w, r, http.StatusOK, "image/png", img, cardMaxAge)
+		default:
+			svg := cards.RenderHeatmapSVG(subject.stats.ContributionCalendar, opts)
+			writeCard(w, r, http.StatusOK, "image/svg+xml", svg, cardMaxAge)
+		}
+	}
+}
+
+// parseDateParam parses an optional YYYY-MM-DD query parameter
+func parseDateParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
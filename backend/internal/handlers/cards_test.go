@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetHeatmapRejectsInvalidRanges(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"inverted", "from=2025-02-01&to=2025-01-01"},
+		{"too long", "from=2020-01-01&to=2025-01-01"},
+		{"bad date", "from=yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			// Ranges are checked before the database is used
+			GetHeatmap(nil)(rec, httptest.NewRequest(http.MethodGet, "/heatmap.svg?"+tt.query, nil))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/providers"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/snapshots"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// Comparison limits
+const (
+	minCompareUsers = 2
+	maxCompareUsers = 5
+	// maxCompareFetches caps concurrent GitHub fetches so one comparison
+	// doesn't burn through the caller's rate limit in a burst
+	maxCompareFetches = 3
+)
+
+// Sources of compared stats
+const (
+	compareSourceSnapshot = "snapshot"
+	compareSourceGithub   = "github"
+)
+
+// githubLoginPattern matches valid GitHub logins
+var githubLoginPattern = regexp.MustCompile(`^[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,37}[a-zA-Z0-9])?$`)
+
+// ComparedUser is a single developer in a comparison
+type ComparedUser struct {
+	Login   string                   `json:"login"`
+	Source  string                   `json:"source"`            // snapshot for opted-in users, github otherwise
+	UserID  *uint                    `json:"user_id,omitempty"` // Set for opted-in users
+	Profile *github.UserProfileStats `json:"profile"`
+	Rank    github.RankInfo          `json:"rank"`
+	Values  map[string]int           `json:"values"`
+	Deltas  map[string]int           `json:"deltas"` // Difference from the first user
+	Radar   map[string]float64       `json:"radar"`  // Values scaled to 0-100 against the highest in the comparison
+}
+
+// CompareResponse represents a side-by-side developer comparison
+type CompareResponse struct {
+	Metrics  []string       `json:"metrics"`
+	Baseline string         `json:"baseline"`
+	Users    []ComparedUser `json:"users"`
+}
+
+// CompareGithubUsers compares the stats of several developers. Opted-in users
+// are compared using their latest snapshot; other logins are fetched from
+// GitHub with the caller's token, sharing the lookup cache.
+func CompareGithubUsers(db *gorm.DB, registry providers.Registry, cache *github.ProfileCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		logins, errMessage := parseCompareLogins(r.URL.Query().Get("users"))
+		if errMessage != "" {
+			utils.RespondError(w, http.StatusBadRequest, errMessage)
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, userID).Error; err != nil {
+			utils.RespondError(w, http.StatusNotFound, "User not found")
+			return
+		}
+
+		if user.GithubUsername == "" || user.GithubToken == "" {
+			utils.RespondError(w, http.StatusBadRequest, "GitHub username or token not configured. Please update your profile first.")
+			return
+		}
+		host := user.GithubHost
+		if host == "" {
+			host = github.DefaultHost
+		}
+
+		period := github.DefaultTimeRange(time.Now())
+		compared := make([]ComparedUser, len(logins))
+		errs := make([]error, len(logins))
+		slots := make(chan struct{}, maxCompareFetches)
+		var wg sync.WaitGroup
+
+		ctx, cancel := fetchContext(r)
+		defer cancel()
+
+		for i, login := range logins {
+			// Opted-in users on the same host are compared from their snapshot
+			if c, ok := compareFromSnapshot(db, login, user.GithubHost); ok {
+				compared[i] = c
+				continue
+			}
+
+			wg.Add(1)
+			go func(i int, login string) {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+
+				stats, _, _, err := lookupGithubUser(ctx, registry, cache, &user, host, login, period)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				compared[i] = ComparedUser{Login: stats.Login, Source: compareSourceGithub, Profile: stats}
+			}(i, login)
+		}
+		wg.Wait()
+
+		for i, err := range errs {
+			if err != nil {
+				respondFetchError(w, models.GithubAccount{Username: logins[i], Host: host}, err)
+				return
+			}
+		}
+
+		metrics := append([]string{"score"}, github.MetricNames()...)
+		compareUsers(compared, metrics)
+
+		utils.RespondSuccess(w, CompareResponse{
+			Metrics:  metrics,
+			Baseline: compared[0].Login,
+			Users:    compared,
+		})
+	}
+}
+
+// parseCompareLogins parses the comma-separated users parameter, returning an
+// error message for invalid input
+func parseCompareLogins(param string) ([]string, string) {
+	seen := make(map[string]bool)
+	var logins []string
+	for _, login := range strings.Split(param, ",") {
+		login = strings.TrimSpace(login)
+		if login == "" || seen[strings.ToLower(login)] {
+			continue
+		}
+		if !githubLoginPattern.MatchString(login) {
+			return nil, "Invalid GitHub login: " + login
+		}
+		seen[strings.ToLower(login)] = true
+		logins = append(logins, login)
+	}
+
+	if len(logins) < minCompareUsers || len(logins) > maxCompareUsers {
+		return nil, "Provide between 2 and 5 distinct GitHub logins in users"
+	}
+	return logins, ""
+}
+
+// compareFromSnapshot returns an opted-in user's latest snapshot for a login.
+// The primary account's host is stored empty for github.com.
+func compareFromSnapshot(db *gorm.DB, login, githubHost string) (ComparedUser, bool) {
+	var user models.User
+	err := db.Where("LOWER(github_username) = ? AND leaderboard_opt_in = ? AND COALESCE(github_host, '') = ?",
+		strings.ToLower(login), true, githubHost).First(&user).Error
+	if err != nil {
+		return ComparedUser{}, false
+	}
+
+	snapshot, err := snapshots.Latest(db, user.ID)
+	if err != nil {
+		return ComparedUser{}, false
+	}
+	stats, err := snapshots.Decode(snapshot)
+	if err != nil {
+		return ComparedUser{}, false
+	}
+	// The snapshot was fetched with the user's token, whose rate limit isn't
+	// the viewer's business
+	stats.RateLimit = nil
+	stats = publicStats(&user, stats)
+
+	return ComparedUser{Login: stats.Login, Source: compareSourceSnapshot, UserID: &user.ID, Profile: stats}, true
+}
+
+// compareUsers fills in ranks, metric values, deltas from the first user and
+// radar values scaled against the highest value of each metric
+func compareUsers(compared []ComparedUser, metrics []string) {
+	highest := make(map[string]int, len(metrics))
+	for i := range compared {
+		c := &compared[i]
+		c.Rank = github.CalculateRank(*c.Profile)
+		c.Values = make(map[string]int, len(metrics))
+		for _, metric := range metrics {
+			value := c.Rank.Score
+			if metric != "score" {
+				value, _ = github.MetricValue(*c.Profile, metric)
+			}
+			c.Values[metric] = value
+			if value > highest[metric] {
+				highest[metric] = value
+			}
+		}
+	}
+
+	for i := range compared {
+		c := &compared[i]
+		c.Deltas = make(map[string]int, len(metrics))
+		c.Radar = make(map[string]float64, len(metrics))
+		for _, metric := range metrics {
+			c.Deltas[metric] = c.Values[metric] - compared[0].Values[metric]
+			if highest[metric] > 0 {
+				c.Radar[metric] = math.Round(float64(c.Values[metric])*1000/float64(highest[metric])) / 10
+			} else {
+				c.Radar[metric] = 0
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/dataexport"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// DataExportResponse represents a personal data export's status. DownloadPath
+// is set once the archive is ready and is relative to the API base URL.
+type DataExportResponse struct {
+	models.DataExport
+	DownloadPath string `json:"download_path,omitempty"`
+}
+
+// newDataExportResponse adds a signed download link to ready exports
+func newDataExportResponse(exporter *dataexport.Exporter, record *models.DataExport) DataExportResponse {
+	response := DataExportResponse{DataExport: *record}
+	if record.Status == models.DataExportReady && record.ExpiresAt != nil {
+		response.DownloadPath = fmt.Sprintf("/exports/%d/download?%s", record.ID, exporter.SignedQuery(record))
+	}
+	return response
+}
+
+// RequestDataExport starts assembling an archive of everything stored about
+// the authenticated user. Poll GetDataExport for the download link.
+func RequestDataExport(exporter *dataexport.Exporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		record, err := exporter.Start(userID)
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to start data export")
+			return
+		}
+
+		utils.RespondSuccess(w, newDataExportResponse(exporter, record))
+	}
+}
+
+// GetDataExport returns the status of one of the authenticated user's data exports
+func GetDataExport(exporter *dataexport.Exporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid export ID")
+			return
+		}
+
+		record, err := exporter.Get(userID, uint(id))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				utils.RespondError(w, http.StatusNotFound, "Export not found")
+				return
+			}
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to retrieve export")
+			return
+		}
+
+		utils.RespondSuccess(w, newDataExportResponse(exporter, record))
+	}
+}
+
+// DownloadDataExport serves a data export archive. The link is authorized by
+// its signature rather than a bearer token, so it works from a browser.
+func DownloadDataExport(exporter *dataexport.Exporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid export ID")
+			return
+		}
+
+		query := r.URL.Query()
+		record, file, err := exporter.Open(uint(id), query.Get("expires"), query.Get("signature"))
+		if err != nil {
+			if errors.Is(err, dataexport.ErrInvalidSignature) {
+				utils.RespondError(w, http.StatusForbidden, "Download link is invalid or has expired")
+				return
+			}
+			utils.RespondError(w, http.StatusNotFound, "Export not found")
+			return
+		}
+		defer file.Close()
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="data-export-%s.zip"`, record.CreatedAt.UTC().Format("2006-01-02")))
+		w.Header().Set("Content-Length", strconv.FormatInt(record.Size, 10))
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+
+		if _, err := io.Copy(w, file); err != nil {
+			log.Printf("Failed to send data export %d: %v", record.ID, err)
+		}
+	}
+}
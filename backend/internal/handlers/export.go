@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/export"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/snapshots"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// startExport writes the headers for a streamed export download
+func startExport(w http.ResponseWriter, format export.Format, name string) {
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.%s"`, name, time.Now().UTC().Format("2006-01-02"), format))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+// ExportContributions streams the authenticated user's contribution calendar
+// from their latest snapshot as CSV, JSON Lines or iCalendar. The format is
+// taken from the route's {format} parameter.
+func ExportContributions(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		snap, err := snapshots.Latest(db, userID)
+		if err != nil {
+			utils.RespondError(w, http.StatusNotFound, "No GitHub stats yet. Fetch your GitHub profile first.")
+			return
+		}
+		stats, err := snapshots.Decode(snap)
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load contributions")
+			return
+		}
+
+		format := export.Format(chi.URLParam(r, "format"))
+		startExport(w, format, "contributions")
+
+		if format == export.ICS {
+			err = export.WriteCalendarICS(w, stats.Login, stats.ContributionCalendar, snap.CreatedAt)
+		} else {
+			var writer *export.Writer
+			if writer, err = export.NewWriter(w, format, export.CalendarHeader); err == nil {
+				err = export.WriteCalendar(writer, stats.ContributionCalendar)
+			}
+		}
+		if err != nil {
+			log.Printf("Failed to export contributions for user %d: %v", userID, err)
+		}
+	}
+}
+
+// ExportRankHistory streams the authenticated user's rank and scored metrics
+// from every snapshot, oldest first, as CSV or JSON Lines
+func ExportRankHistory(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		// Snapshots are read row by row, leaving out the full stats payload
+		rows, err := db.Model(&models.GithubSnapshot{}).
+			Select("id, user_id, login, commits, pull_requests, issues, reviews, stars, followers, score, rank, created_at").
+			Where("user_id = ?", userID).
+			Order("created_at").
+			Rows()
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load rank history")
+			return
+		}
+		defer rows.Close()
+
+		format := export.Format(chi.URLParam(r, "format"))
+		startExport(w, format, "rank-history")
+
+		writer, err := export.NewWriter(w, format, export.RankHistoryHeader)
+		if err != nil {
+			log.Printf("Failed to export rank history for user %d: %v", userID, err)
+			return
+		}
+
+		for rows.Next() {
+			var snap models.GithubSnapshot
+			if err := db.ScanRows(rows, &snap); err != nil {
+				log.Printf("Failed to export rank history for user %d: %v", userID, err)
+				return
+			}
+			if err := writer.Write(export.NewRankPoint(snap)); err != nil {
+				log.Printf("Failed to export rank history for user %d: %v", userID, err)
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			log.Printf("Failed to export rank history for user %d: %v", userID, err)
+		}
+		if err := writer.Flush(); err != nil {
+			log.Printf("Failed to export rank history for user %d: %v", userID, err)
+		}
+	}
+}
+
+// GetContributionFeed serves a public iCalendar feed of a user's daily
+// contribution totals, for subscribing from calendar apps. It follows the
+// same privacy settings as the stats card.
+func GetContributionFeed(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := findPublicUser(db, chi.URLParam(r, "handle"))
+		if err != nil || !user.Privacy.ShowGithubStats {
+			utils.RespondError(w, http.StatusNotFound, "Profile not found")
+			return
+		}
+
+		snap, err := snapshots.Latest(db, user.ID)
+		if err != nil {
+			utils.RespondError(w, http.StatusNotFound, "No GitHub stats yet")
+			return
+		}
+		stats, err := snapshots.Decode(snap)
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load contributions")
+			return
+		}
+		stats = publicStats(user, stats)
+
+		w.Header().Set("Content-Type", export.ICS.ContentType())
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", cardMaxAge))
+		w.WriteHeader(http.StatusOK)
+
+		if err := export.WriteCalendarICS(w, stats.Login, stats.ContributionCalendar, snap.CreatedAt); err != nil {
+			log.Printf("Failed to write contribution feed for user %d: %v", user.ID, err)
+		}
+	}
+}
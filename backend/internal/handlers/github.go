@@ -1,17 +1,38 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/amilcar-vasquez/auth-service/backend/internal/achievements"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/gitea"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/gitlab"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/providers"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/snapshots"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
 	"gorm.io/gorm"
 )
 
-// GetGithubProfile fetches GitHub profile statistics for the authenticated user
-func GetGithubProfile(db *gorm.DB) http.HandlerFunc {
+// forgeFetchTimeout bounds the forge queries made while handling a request,
+// leaving time to respond before the server's write timeout
+const forgeFetchTimeout = 10 * time.Second
+
+// fetchContext returns the request's context with the forge fetch deadline
+func fetchContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), forgeFetchTimeout)
+}
+
+// GetGithubProfile fetches GitHub profile statistics for the authenticated
+// user, combined with any other connected forge accounts
+func GetGithubProfile(db *gorm.DB, registry providers.Registry) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get user from context (set by auth middleware)
 		userID, ok := middleware.GetUserIDFromContext(r)
@@ -20,38 +41,197 @@ func GetGithubProfile(db *gorm.DB) http.HandlerFunc {
 			return
 		}
 
+		// Parse the optional time window
+		query := r.URL.Query()
+		period, err := github.ParseTimeRange(query.Get("window"), query.Get("year"), query.Get("from_year"), query.Get("to_year"), time.Now())
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Optionally count stars from organization repositories the user contributes to
+		includeContributed := query.Get("include_contributed") == "true"
+
+		// Optionally override the default ignored languages
+		var ignoredLanguages []string
+		if ignore, ok := query["ignore_languages"]; ok {
+			ignoredLanguages = []string{}
+			for _, name := range strings.Split(strings.Join(ignore, ","), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					ignoredLanguages = append(ignoredLanguages, name)
+				}
+			}
+		}
+
+		// Snapshots, percentiles and curve ranks are based on year-to-date
+		// stats from the user's own repositories
+		defaultPeriod := query.Get("window") == "" && query.Get("year") == "" &&
+			query.Get("from_year") == "" && query.Get("to_year") == "" && !includeContributed && ignoredLanguages == nil
+
 		var user models.User
 		if err := db.First(&user, userID).Error; err != nil {
 			utils.RespondError(w, http.StatusNotFound, "User not found")
 			return
 		}
 
+		// Collect the primary and any additional connected accounts
+		accounts, err := providers.ConnectedAccounts(db, &user)
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load GitHub accounts")
+			return
+		}
+
 		// Check if user has GitHub credentials configured
-		if user.GithubUsername == "" || user.GithubToken == "" {
+		if len(accounts) == 0 {
 			utils.RespondError(w, http.StatusBadRequest, "GitHub username or token not configured. Please update your profile first.")
 			return
 		}
 
-		// Fetch GitHub profile stats
-		stats, err := github.FetchUserProfile(user.GithubUsername, user.GithubToken)
-		if err != nil {
-			utils.RespondError(w, http.StatusInternalServerError, "Failed to fetch GitHub profile: "+err.Error())
-			return
+		// Fetch profile stats for the requested period from each account
+		ctx, cancel := fetchContext(r)
+		defer cancel()
+		fetched := make([]*github.UserProfileStats, 0, len(accounts))
+		for _, account := range accounts {
+			fetcher, err := registry.Get(account.Provider)
+			if err != nil {
+				utils.RespondError(w, http.StatusInternalServerError, "Unsupported provider for "+account.Username+"@"+account.Host)
+				return
+			}
+
+			stats, err := fetcher.FetchUserProfile(ctx, account.Username, account.Token, github.FetchOptions{
+				Host:                    account.Host,
+				Period:                  period,
+				IncludeContributedRepos: includeContributed,
+				IgnoredLanguages:        ignoredLanguages,
+				IncludePrivate:          user.Privacy.IncludePrivateContributions,
+			})
+			if err != nil {
+				// Serve the last snapshot while the token's budget is exhausted
+				var limitErr *github.RateLimitError
+				if errors.As(err, &limitErr) && defaultPeriod && respondStaleProfile(w, db, user.ID, accounts, limitErr) {
+					return
+				}
+				respondFetchError(w, account, err)
+				return
+			}
+			fetched = append(fetched, stats)
 		}
 
+		// Combine all accounts into one profile and rank
+		stats := github.CombineStats(fetched)
+
 		// Calculate developer rank
 		rank := github.CalculateRank(*stats)
 
+		var percentile *github.PercentileInfo
+		newAchievements := make([]models.Achievement, 0)
+		if defaultPeriod {
+			// Store a snapshot for percentiles and leaderboards, and award any
+			// achievements it meets
+			snapshot, err := snapshots.Save(db, user.ID, stats, rank)
+			if err != nil {
+				log.Printf("Failed to save GitHub snapshot for user %d: %v", user.ID, err)
+			} else if newAchievements, err = achievements.Evaluate(db, snapshot); err != nil {
+				log.Printf("Failed to evaluate achievements for user %d: %v", user.ID, err)
+			}
+
+			// Compare against the latest snapshot of every registered user
+			latest, err := snapshots.LatestForAll(db)
+			if err != nil {
+				log.Printf("Failed to load GitHub snapshots: %v", err)
+			} else {
+				population := make([]github.UserProfileStats, 0, len(latest))
+				for _, s := range latest {
+					population = append(population, snapshots.Summary(s))
+				}
+
+				// The population is public counts only, so compare like with like
+				public := stats.WithoutPrivate()
+				info := github.CalculatePercentiles(public, population)
+				percentile = &info
+
+				// Assign tiers by percentile band when curve mode is requested
+				if query.Get("mode") == github.RankModeCurve {
+					rank = github.CalculateCurveRank(public, population)
+				}
+			}
+		}
+
 		// Return stats with rank information
 		response := map[string]interface{}{
-			"profile": stats,
-			"rank":    rank,
+			"profile":          stats,
+			"rank":             rank,
+			"percentile":       percentile,
+			"analytics":        github.AnalyzeContributions(stats.ContributionCalendar),
+			"accounts":         accounts,
+			"stale":            false,
+			"new_achievements": newAchievements,
 		}
 
 		utils.RespondSuccess(w, response)
 	}
 }
 
+// respondFetchError maps a forge fetch error to an HTTP status and a generic
+// message. The full error can include upstream URLs, so it is only logged.
+func respondFetchError(w http.ResponseWriter, account models.GithubAccount, err error) {
+	name := account.Username
+	if account.Host != "" {
+		name += "@" + account.Host
+	}
+	log.Printf("Failed to fetch forge profile for %s: %v", name, err)
+
+	var limitErr *github.RateLimitError
+	var upstreamErr *github.UpstreamError
+	switch {
+	case errors.As(err, &limitErr):
+		if !limitErr.ResetAt.IsZero() {
+			retryAfter := int(time.Until(limitErr.ResetAt).Seconds()) + 1
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			w.Header().Set("Retry-After", fmt.Sprint(retryAfter))
+		}
+		utils.RespondError(w, http.StatusTooManyRequests, "Rate limit reached while fetching the profile for "+name+". Please try again later.")
+	case errors.Is(err, github.ErrUserNotFound), errors.Is(err, gitlab.ErrUserNotFound), errors.Is(err, gitea.ErrUserNotFound):
+		utils.RespondError(w, http.StatusNotFound, "No user named "+name+" was found")
+	case errors.Is(err, github.ErrTimeout):
+		utils.RespondError(w, http.StatusGatewayTimeout, "Timed out fetching the profile for "+name)
+	case errors.As(err, &upstreamErr) && (upstreamErr.StatusCode == http.StatusUnauthorized || upstreamErr.StatusCode == http.StatusForbidden):
+		utils.RespondError(w, http.StatusBadGateway, "The access token for "+name+" was rejected. Please check your credentials.")
+	case errors.As(err, &upstreamErr):
+		utils.RespondError(w, http.StatusBadGateway, "The forge returned an error for "+name+". Please try again later.")
+	default:
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to fetch the profile for "+name)
+	}
+}
+
+// respondStaleProfile responds with the user's latest snapshot marked as
+// stale, returning false if there is no usable snapshot
+func respondStaleProfile(w http.ResponseWriter, db *gorm.DB, userID uint, accounts []models.GithubAccount, limitErr *github.RateLimitError) bool {
+	snapshot, err := snapshots.Latest(db, userID)
+	if err != nil {
+		return false
+	}
+	stats, err := snapshots.Decode(snapshot)
+	if err != nil {
+		log.Printf("Failed to decode GitHub snapshot %d: %v", snapshot.ID, err)
+		return false
+	}
+
+	utils.RespondSuccess(w, map[string]interface{}{
+		"profile":     stats,
+		"rank":        github.CalculateRank(*stats),
+		"percentile":  nil,
+		"analytics":   github.AnalyzeContributions(stats.ContributionCalendar),
+		"accounts":    accounts,
+		"stale":       true,
+		"snapshot_at": snapshot.CreatedAt,
+		"retry_at":    limitErr.ResetAt,
+	})
+	return true
+}
+
 // UpdateGithubCredentials updates the user's GitHub username and token
 func UpdateGithubCredentials(db *gorm.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -63,8 +243,9 @@ func UpdateGithubCredentials(db *gorm.DB) http.HandlerFunc {
 		}
 
 		var req struct {
-			GithubUsername string `json:"github_username"`
-			GithubToken    string `json:"github_token"`
+			GithubUsername string  `json:"github_username"`
+			GithubToken    string  `json:"github_token"`
+			GithubHost     *string `json:"github_host"`
 		}
 
 		if err := utils.ParseJSON(r, &req); err != nil {
@@ -73,11 +254,21 @@ func UpdateGithubCredentials(db *gorm.DB) http.HandlerFunc {
 		}
 
 		// Validate that at least one field is provided
-		if req.GithubUsername == "" && req.GithubToken == "" {
+		if req.GithubUsername == "" && req.GithubToken == "" && req.GithubHost == nil {
 			utils.RespondError(w, http.StatusBadRequest, "GitHub username or token required")
 			return
 		}
 
+		// Validate the host; only github.com and configured enterprise hosts are allowed
+		var host string
+		if req.GithubHost != nil {
+			var err error
+			if host, err = github.NormalizeHost(*req.GithubHost); err != nil {
+				utils.RespondError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
 		// Update user's GitHub credentials
 		var user models.User
 		if err := db.First(&user, userID).Error; err != nil {
@@ -92,6 +283,13 @@ func UpdateGithubCredentials(db *gorm.DB) http.HandlerFunc {
 		if req.GithubToken != "" {
 			user.GithubToken = req.GithubToken
 		}
+		if req.GithubHost != nil {
+			// Store github.com as empty for existing rows
+			if host == github.DefaultHost {
+				host = ""
+			}
+			user.GithubHost = host
+		}
 
 		if err := db.Save(&user).Error; err != nil {
 			utils.RespondError(w, http.StatusInternalServerError, "Failed to update GitHub credentials")
@@ -101,6 +299,7 @@ func UpdateGithubCredentials(db *gorm.DB) http.HandlerFunc {
 		utils.RespondSuccess(w, map[string]interface{}{
 			"message":         "GitHub credentials updated successfully",
 			"github_username": user.GithubUsername,
+			"github_host":     user.GithubHost,
 		})
 	}
 }
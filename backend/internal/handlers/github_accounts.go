@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/providers"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// ListGithubAccounts lists the authenticated user's connected GitHub accounts
+func ListGithubAccounts(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, userID).Error; err != nil {
+			utils.RespondError(w, http.StatusNotFound, "User not found")
+			return
+		}
+
+		accounts, err := providers.ConnectedAccounts(db, &user)
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load GitHub accounts")
+			return
+		}
+
+		utils.RespondSuccess(w, accounts)
+	}
+}
+
+// AddGithubAccount connects an additional GitHub, GitHub Enterprise, GitLab or Gitea account
+func AddGithubAccount(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		var req struct {
+			Provider string `json:"provider"`
+			Host     string `json:"host"`
+			Username string `json:"username"`
+			Token    string `json:"token"`
+		}
+
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		req.Username = strings.TrimSpace(req.Username)
+		if req.Username == "" || req.Token == "" {
+			utils.RespondError(w, http.StatusBadRequest, "GitHub username and token required")
+			return
+		}
+
+		provider := strings.ToLower(strings.TrimSpace(req.Provider))
+		if provider == "" {
+			provider = providers.GitHub
+		}
+
+		// Only public instances and configured self-hosted instances are allowed
+		host, err := providers.NormalizeHost(provider, req.Host)
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, userID).Error; err != nil {
+			utils.RespondError(w, http.StatusNotFound, "User not found")
+			return
+		}
+
+		// Reject the primary account, whose stats are already counted
+		candidate := models.GithubAccount{Provider: provider, Host: host, Username: req.Username}
+		if primary, ok := providers.PrimaryAccount(&user); ok && providers.SameAccount(primary, candidate) {
+			utils.RespondError(w, http.StatusConflict, "This is already your primary GitHub account")
+			return
+		}
+
+		// Reject duplicates of an already connected account
+		var existing models.GithubAccount
+		err = db.Where("user_id = ? AND provider = ? AND host = ? AND LOWER(username) = LOWER(?)", userID, provider, host, req.Username).First(&existing).Error
+		if err == nil {
+			utils.RespondError(w, http.StatusConflict, "GitHub account already connected")
+			return
+		}
+
+		account := models.GithubAccount{
+			UserID:   userID,
+			Provider: provider,
+			Host:     host,
+			Username: req.Username,
+			Token:    req.Token,
+		}
+
+		if err := db.Create(&account).Error; err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to connect GitHub account")
+			return
+		}
+
+		utils.RespondSuccess(w, account)
+	}
+}
+
+// DeleteGithubAccount disconnects an additional GitHub account
+func DeleteGithubAccount(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		accountID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid account ID")
+			return
+		}
+
+		result := db.Where("id = ? AND user_id = ?", accountID, userID).Delete(&models.GithubAccount{})
+		if result.Error != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to disconnect GitHub account")
+			return
+		}
+		if result.RowsAffected == 0 {
+			utils.RespondError(w, http.StatusNotFound, "GitHub account not found")
+			return
+		}
+
+		utils.RespondSuccessWithMessage(w, "GitHub account disconnected")
+	}
+}
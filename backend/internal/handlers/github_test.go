@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+)
+
+func TestRespondFetchErrorHidesUpstreamDetails(t *testing.T) {
+	internal := errors.New(`Post "https://ghe.internal.example/api/graphql": dial tcp 10.0.0.5:443: connection refused`)
+	account := models.GithubAccount{Username: "octocat", Host: "ghe.internal.example"}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"rate limit", &github.RateLimitError{ResetAt: time.Now().Add(time.Minute)}, http.StatusTooManyRequests},
+		{"not found", github.ErrUserNotFound, http.StatusNotFound},
+		{"timeout", github.ErrTimeout, http.StatusGatewayTimeout},
+		{"bad credentials", &github.UpstreamError{StatusCode: http.StatusUnauthorized, Err: internal}, http.StatusBadGateway},
+		{"upstream", &github.UpstreamError{StatusCode: http.StatusInternalServerError, Err: internal}, http.StatusBadGateway},
+		{"other", internal, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			respondFetchError(rec, account, tt.err)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			body := rec.Body.String()
+			if strings.Contains(body, "10.0.0.5") || strings.Contains(body, "/api/graphql") {
+				t.Errorf("response leaks upstream error: %s", body)
+			}
+		})
+	}
+}
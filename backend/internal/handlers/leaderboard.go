@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/snapshots"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// Pagination defaults
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var (
+	errInvalidPage    = errors.New("page must be a positive integer")
+	errInvalidPerPage = errors.New("per_page must be between 1 and 100")
+)
+
+// leaderboardMetrics lists the metrics users can be ranked by
+var leaderboardMetrics = map[string]bool{
+	"score":         true,
+	"commits":       true,
+	"pull_requests": true,
+	"reviews":       true,
+	"stars":         true,
+}
+
+// LeaderboardEntry represents a single user's position on the leaderboard
+type LeaderboardEntry struct {
+	Position    int    `json:"position"`
+	UserID      uint   `json:"user_id"`
+	Name        string `json:"name"`
+	GithubLogin string `json:"github_login"`
+	Value       int    `json:"value"`
+	Rank        string `json:"rank"`
+}
+
+// LeaderboardResponse represents a page of the leaderboard
+type LeaderboardResponse struct {
+	Metric  string             `json:"metric"`
+	Window  string             `json:"window"`
+	Page    int                `json:"page"`
+	PerPage int                `json:"per_page"`
+	Total   int                `json:"total"`
+	Entries []LeaderboardEntry `json:"entries"`
+}
+
+// GetLeaderboard ranks opted-in users by score or a single metric using
+// cached GitHub snapshots
+func GetLeaderboard(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		metric := strings.ToLower(query.Get("metric"))
+		if metric == "" {
+			metric = "score"
+		}
+		if !leaderboardMetrics[metric] {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid metric. Use score, commits, pull_requests, reviews or stars")
+			return
+		}
+
+		window := strings.ToLower(query.Get("window"))
+		if window == "" {
+			window = snapshots.WindowAll
+		}
+		windowStart, ok := snapshots.WindowStart(window, time.Now())
+		if !ok {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid window. Use all, week, month or year")
+			return
+		}
+
+		page, perPage, err := parsePagination(query.Get("page"), query.Get("per_page"))
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// Load opted-in users
+		var users []models.User
+		if err := db.Where("leaderboard_opt_in = ?", true).Order("id").Find(&users).Error; err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load leaderboard")
+			return
+		}
+
+		userIDs := make([]uint, 0, len(users))
+		for _, u := range users {
+			userIDs = append(userIDs, u.ID)
+		}
+
+		windowed, err := snapshots.ForWindow(db, userIDs, windowStart)
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load leaderboard")
+			return
+		}
+
+		entries := rankLeaderboard(users, windowed, metric)
+
+		// Paginate
+		start := (page - 1) * perPage
+		if start > len(entries) {
+			start = len(entries)
+		}
+		end := start + perPage
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		utils.RespondSuccess(w, LeaderboardResponse{
+			Metric:  metric,
+			Window:  window,
+			Page:    page,
+			PerPage: perPage,
+			Total:   len(entries),
+			Entries: entries[start:end],
+		})
+	}
+}
+
+// rankLeaderboard orders users with windowed stats by a metric, highest first
+func rankLeaderboard(users []models.User, windowed map[uint]github.UserProfileStats, metric string) []LeaderboardEntry {
+	entries := make([]LeaderboardEntry, 0, len(windowed))
+	for _, user := range users {
+		stats, ok := windowed[user.ID]
+		if !ok {
+			continue
+		}
+		rank := github.CalculateRank(stats)
+
+		value := rank.Score
+		if metric != "score" {
+			value, _ = github.MetricValue(stats, metric)
+		}
+
+		entries = append(entries, LeaderboardEntry{
+			UserID:      user.ID,
+			Name:        user.Name,
+			GithubLogin: stats.Login,
+			Value:       value,
+			Rank:        rank.Rank,
+		})
+	}
+
+	// Highest value first, ties broken by name for a stable order
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Value != entries[j].Value {
+			return entries[i].Value > entries[j].Value
+		}
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+
+	// Tied users share a position (1, 2, 2, 4)
+	for i := range entries {
+		if i > 0 && entries[i].Value == entries[i-1].Value {
+			entries[i].Position = entries[i-1].Position
+		} else {
+			entries[i].Position = i + 1
+		}
+	}
+
+	return entries
+}
+
+// parsePagination parses page and per_page query parameters with defaults
+func parsePagination(pageParam, perPageParam string) (int, int, error) {
+	page, perPage := 1, defaultPageSize
+
+	if pageParam != "" {
+		p, err := strconv.Atoi(pageParam)
+		if err != nil || p < 1 {
+			return 0, 0, errInvalidPage
+		}
+		page = p
+	}
+
+	if perPageParam != "" {
+		pp, err := strconv.Atoi(perPageParam)
+		if err != nil || pp < 1 || pp > maxPageSize {
+			return 0, 0, errInvalidPerPage
+		}
+		perPage = pp
+	}
+
+	return page, perPage, nil
+}
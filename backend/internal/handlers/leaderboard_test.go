@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github/githubtest"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/snapshots"
+)
+
+func TestLeaderboardIgnoresPrivateContributions(t *testing.T) {
+	octocat, err := githubtest.Fixture("octocat")
+	if err != nil {
+		t.Fatalf("loading fixture: %v", err)
+	}
+	server := githubtest.NewServer(octocat)
+	defer server.Close()
+
+	client := github.NewClient(server.URL, server.Client())
+	period := github.TimeRange{From: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 1, 6, 23, 59, 59, 0, time.UTC)}
+	now := time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)
+	users := []models.User{{ID: 1, Name: "Octocat"}}
+
+	// leaderboard ranks octocat from a snapshot fetched with or without private counting
+	leaderboard := func(includePrivate bool, metric string) ([]LeaderboardEntry, github.UserProfileStats) {
+		stats, err := client.FetchUserProfile(context.Background(), "octocat", octocat.Token, github.FetchOptions{Period: period, IncludePrivate: includePrivate})
+		if err != nil {
+			t.Fatalf("FetchUserProfile: %v", err)
+		}
+		snapshot, err := snapshots.New(1, stats, github.CalculateRank(*stats), now)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		windowed := map[uint]github.UserProfileStats{1: snapshots.Delta(*snapshot, nil, time.Time{})}
+		return rankLeaderboard(users, windowed, metric), *stats
+	}
+
+	for _, metric := range []string{"score", "commits", "pull_requests", "reviews"} {
+		public, publicStats := leaderboard(false, metric)
+		private, privateStats := leaderboard(true, metric)
+
+		// The fixture has private contributions, so the totals differ
+		if privateStats.TotalCommits == publicStats.TotalCommits {
+			t.Fatalf("private counting didn't change the fetched totals")
+		}
+		if !reflect.DeepEqual(public, private) {
+			t.Errorf("%s leaderboard = %+v with private counting, want %+v", metric, private, public)
+		}
+	}
+}
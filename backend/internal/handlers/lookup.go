@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/providers"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// GetGithubUser fetches profile statistics and a rank for any GitHub login
+// using the authenticated user's token. Results are cached per login.
+func GetGithubUser(db *gorm.DB, registry providers.Registry, cache *github.ProfileCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		login := chi.URLParam(r, "login")
+		if !githubLoginPattern.MatchString(login) {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid GitHub login")
+			return
+		}
+
+		// Parse the optional time window
+		query := r.URL.Query()
+		period, err := github.ParseTimeRange(query.Get("window"), query.Get("year"), query.Get("from_year"), query.Get("to_year"), time.Now())
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, userID).Error; err != nil {
+			utils.RespondError(w, http.StatusNotFound, "User not found")
+			return
+		}
+
+		if user.GithubUsername == "" || user.GithubToken == "" {
+			utils.RespondError(w, http.StatusBadRequest, "GitHub username or token not configured. Please update your profile first.")
+			return
+		}
+		host := user.GithubHost
+		if host == "" {
+			host = github.DefaultHost
+		}
+
+		ctx, cancel := fetchContext(r)
+		defer cancel()
+
+		stats, fetchedAt, cached, err := lookupGithubUser(ctx, registry, cache, &user, host, login, period)
+		if err != nil {
+			respondFetchError(w, models.GithubAccount{Username: login, Host: host}, err)
+			return
+		}
+
+		utils.RespondSuccess(w, map[string]interface{}{
+			"profile":    stats,
+			"rank":       github.CalculateRank(*stats),
+			"analytics":  github.AnalyzeContributions(stats.ContributionCalendar),
+			"cached":     cached,
+			"fetched_at": fetchedAt,
+		})
+	}
+}
+
+// lookupGithubUser returns a login's profile from the cache or GitHub. A
+// user's own profile may include private contributions visible only to their
+// token, so it is never cached or served from the cache.
+func lookupGithubUser(ctx context.Context, registry providers.Registry, cache *github.ProfileCache, user *models.User, host, login string, period github.TimeRange) (*github.UserProfileStats, time.Time, bool, error) {
+	own := strings.EqualFold(login, user.GithubUsername)
+	if !own {
+		if stats, fetchedAt, ok := cache.Get(host, login, period); ok {
+			return stats, fetchedAt, true, nil
+		}
+	}
+
+	fetcher, err := registry.Get(providers.GitHub)
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+
+	stats, err := fetcher.FetchUserProfile(ctx, login, user.GithubToken, github.FetchOptions{
+		Host:   host,
+		Period: period,
+	})
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+
+	// The rate limit belongs to the viewer's token, so other viewers don't get it
+	if !own {
+		shared := *stats
+		shared.RateLimit = nil
+		cache.Set(host, login, period, &shared)
+	}
+	return stats, time.Now(), false, nil
+}
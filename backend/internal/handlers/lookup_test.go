@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github/githubtest"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/providers"
+)
+
+func TestLookupGithubUserIsCached(t *testing.T) {
+	octocat, err := githubtest.Fixture("octocat")
+	if err != nil {
+		t.Fatalf("loading fixture: %v", err)
+	}
+	server := githubtest.NewServer(octocat)
+	defer server.Close()
+
+	registry := providers.Registry{providers.GitHub: github.NewClient(server.URL, server.Client())}
+	cache := github.NewProfileCache(github.DefaultProfileCacheTTL)
+	viewer := &models.User{GithubUsername: "someone-else", GithubToken: octocat.Token}
+
+	// Default periods end at the time of the request
+	first := github.TimeRange{From: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)}
+	second := first
+	second.To = first.To.Add(7 * time.Minute)
+
+	fetched, _, cached, err := lookupGithubUser(context.Background(), registry, cache, viewer, github.DefaultHost, "octocat", first)
+	if err != nil || cached {
+		t.Fatalf("first lookup: cached %v, err %v", cached, err)
+	}
+	if fetched.RateLimit == nil {
+		t.Fatalf("first lookup has no rate limit")
+	}
+	requests := server.Requests()
+
+	stats, _, cached, err := lookupGithubUser(context.Background(), registry, cache, viewer, github.DefaultHost, "OctoCat", second)
+	if err != nil || !cached {
+		t.Fatalf("second lookup: cached %v, err %v", cached, err)
+	}
+	// The viewer's rate limit isn't served to others from the cache
+	if stats.Login != "octocat" || stats.RateLimit != nil {
+		t.Errorf("login = %q, rate limit %+v", stats.Login, stats.RateLimit)
+	}
+	if server.Requests() != requests {
+		t.Errorf("second lookup made %d upstream requests", server.Requests()-requests)
+	}
+}
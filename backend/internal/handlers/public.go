@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/snapshots"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// PublicProfile represents the subset of a user's profile they chose to share
+type PublicProfile struct {
+	Handle      string                   `json:"handle"`
+	Name        string                   `json:"name"`
+	Avatar      string                   `json:"avatar,omitempty"`
+	Email       string                   `json:"email,omitempty"`
+	GithubLogin string                   `json:"github_login,omitempty"`
+	Stats       *github.UserProfileStats `json:"stats,omitempty"`
+	Rank        *github.RankInfo         `json:"rank,omitempty"`
+	// IncludesPrivate is set when stats and rank count private contributions
+	IncludesPrivate bool       `json:"includes_private"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+}
+
+// findPublicUser looks up a user with a public profile by handle
+func findPublicUser(db *gorm.DB, handle string) (*models.User, error) {
+	var user models.User
+	err := db.Where("handle = ? AND privacy_public = ?", strings.ToLower(handle), true).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// publicStats returns the stats a user shares with others, leaving out
+// private contributions unless they chose to show them
+func publicStats(user *models.User, stats *github.UserProfileStats) *github.UserProfileStats {
+	if user.Privacy.SharesPrivateContributions() {
+		return stats
+	}
+	shared := stats.WithoutPrivate()
+	return &shared
+}
+
+// GetPublicProfile returns the publicly visible profile for a handle
+func GetPublicProfile(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, err := findPublicUser(db, chi.URLParam(r, "handle"))
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				utils.RespondError(w, http.StatusNotFound, "Profile not found")
+				return
+			}
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to retrieve profile")
+			return
+		}
+
+		profile := PublicProfile{
+			Handle: *user.Handle,
+			Name:   user.Name,
+			Avatar: user.Avatar,
+		}
+		if user.Privacy.ShowEmail {
+			profile.Email = user.Email
+		}
+
+		// GitHub data comes from the latest snapshot, never from a live call
+		if user.Privacy.ShowGithubStats || user.Privacy.ShowRank {
+			if snap, err := snapshots.Latest(db, user.ID); err == nil {
+				if stats, err := snapshots.Decode(snap); err == nil {
+					stats = publicStats(user, stats)
+					profile.GithubLogin = stats.Login
+					profile.UpdatedAt = &snap.CreatedAt
+					profile.IncludesPrivate = stats.IncludesPrivate
+
+					if user.Privacy.ShowGithubStats {
+						profile.Stats = stats
+					}
+					if user.Privacy.ShowRank {
+						rank := github.CalculateRank(*stats)
+						profile.Rank = &rank
+					}
+				}
+			}
+		}
+
+		utils.RespondSuccess(w, profile)
+	}
+}
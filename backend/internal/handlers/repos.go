@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/providers"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// repositorySorts orders repositories for each sort option, highest first
+var repositorySorts = map[string]func(a, b github.Repository) bool{
+	"stars":    func(a, b github.Repository) bool { return a.StargazerCount > b.StargazerCount },
+	"forks":    func(a, b github.Repository) bool { return a.ForkCount > b.ForkCount },
+	"issues":   func(a, b github.Repository) bool { return a.OpenIssues > b.OpenIssues },
+	"activity": func(a, b github.Repository) bool { return a.RecentCommits > b.RecentCommits },
+	"pushed": func(a, b github.Repository) bool {
+		if a.PushedAt == nil || b.PushedAt == nil {
+			return a.PushedAt != nil
+		}
+		return a.PushedAt.After(*b.PushedAt)
+	},
+	"name": func(a, b github.Repository) bool {
+		return strings.ToLower(a.Name) > strings.ToLower(b.Name)
+	},
+}
+
+// RepositoriesResponse represents a page of the user's repositories
+type RepositoriesResponse struct {
+	Sort         string              `json:"sort"`
+	Order        string              `json:"order"`
+	Page         int                 `json:"page"`
+	PerPage      int                 `json:"per_page"`
+	Total        int                 `json:"total"`
+	Truncated    bool                `json:"truncated"` // More repositories exist than were fetched
+	Repositories []github.Repository `json:"repositories"`
+}
+
+// GetGithubRepos lists the authenticated user's public GitHub repositories
+// with details, filtered and sorted by query parameters
+func GetGithubRepos(db *gorm.DB, registry providers.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		query := r.URL.Query()
+
+		sortBy := strings.ToLower(query.Get("sort"))
+		if sortBy == "" {
+			sortBy = "pushed"
+		}
+		less, ok := repositorySorts[sortBy]
+		if !ok {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid sort. Use stars, forks, issues, activity, pushed or name")
+			return
+		}
+
+		// Names sort A-Z by default, everything else highest first
+		order := strings.ToLower(query.Get("order"))
+		if order == "" {
+			order = "desc"
+			if sortBy == "name" {
+				order = "asc"
+			}
+		}
+		if order != "asc" && order != "desc" {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid order. Use asc or desc")
+			return
+		}
+
+		minStars := 0
+		if param := query.Get("min_stars"); param != "" {
+			var err error
+			if minStars, err = strconv.Atoi(param); err != nil || minStars < 0 {
+				utils.RespondError(w, http.StatusBadRequest, "min_stars must be a non-negative integer")
+				return
+			}
+		}
+
+		page, perPage, err := parsePagination(query.Get("page"), query.Get("per_page"))
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, userID).Error; err != nil {
+			utils.RespondError(w, http.StatusNotFound, "User not found")
+			return
+		}
+
+		if user.GithubUsername == "" || user.GithubToken == "" {
+			utils.RespondError(w, http.StatusBadRequest, "GitHub username or token not configured. Please update your profile first.")
+			return
+		}
+		host := user.GithubHost
+		if host == "" {
+			host = github.DefaultHost
+		}
+
+		provider, err := registry.Get(providers.GitHub)
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "GitHub provider not configured")
+			return
+		}
+		fetcher, ok := provider.(github.RepositoryFetcher)
+		if !ok {
+			utils.RespondError(w, http.StatusNotImplemented, "Repository listing is not supported")
+			return
+		}
+
+		ctx, cancel := fetchContext(r)
+		defer cancel()
+
+		repos, truncated, err := fetcher.FetchRepositories(ctx, user.GithubUsername, user.GithubToken, github.FetchOptions{Host: host})
+		if err != nil {
+			respondFetchError(w, models.GithubAccount{Username: user.GithubUsername, Host: host}, err)
+			return
+		}
+
+		// Filter
+		filter := repositoryFilter{
+			language:        query.Get("language"),
+			topic:           query.Get("topic"),
+			search:          strings.ToLower(query.Get("q")),
+			minStars:        minStars,
+			includeForks:    query.Get("include_forks") == "true",
+			includeArchived: query.Get("include_archived") != "false",
+		}
+		filtered := make([]github.Repository, 0, len(repos))
+		for _, repo := range repos {
+			if filter.matches(repo) {
+				filtered = append(filtered, repo)
+			}
+		}
+
+		// Sort, keeping GitHub's most recently pushed order for ties
+		sort.SliceStable(filtered, func(i, j int) bool {
+			if order == "asc" {
+				return less(filtered[j], filtered[i])
+			}
+			return less(filtered[i], filtered[j])
+		})
+
+		// Paginate
+		start := (page - 1) * perPage
+		if start > len(filtered) {
+			start = len(filtered)
+		}
+		end := start + perPage
+		if end > len(filtered) {
+			end = len(filtered)
+		}
+
+		utils.RespondSuccess(w, RepositoriesResponse{
+			Sort:         sortBy,
+			Order:        order,
+			Page:         page,
+			PerPage:      perPage,
+			Total:        len(filtered),
+			Truncated:    truncated,
+			Repositories: filtered[start:end],
+		})
+	}
+}
+
+// repositoryFilter holds the repository list filters
+type repositoryFilter struct {
+	language        string
+	topic           string
+	search          string
+	minStars        int
+	includeForks    bool
+	includeArchived bool
+}
+
+// matches reports whether a repository passes the filters
+func (f repositoryFilter) matches(repo github.Repository) bool {
+	if repo.IsFork && !f.includeForks {
+		return false
+	}
+	if repo.IsArchived && !f.includeArchived {
+		return false
+	}
+	if repo.StargazerCount < f.minStars {
+		return false
+	}
+	if f.language != "" && !strings.EqualFold(repo.PrimaryLanguage.Name, f.language) {
+		return false
+	}
+	if f.search != "" && !strings.Contains(strings.ToLower(repo.Name+" "+repo.Description), f.search) {
+		return false
+	}
+	if f.topic != "" {
+		found := false
+		for _, topic := range repo.Topics {
+			if strings.EqualFold(topic, f.topic) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
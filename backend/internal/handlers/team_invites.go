@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/confirmation"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/mailer"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// teamInviteTTL is how long a team invitation can be accepted
+const teamInviteTTL = 14 * 24 * time.Hour
+
+// teamInviteSent is the response to every invitation, so it doesn't reveal
+// whether the email belongs to an account or a member
+const teamInviteSent = "Invitation sent. They'll join the team once they accept it."
+
+// AcceptTeamInviteRequest represents the invitation acceptance payload
+type AcceptTeamInviteRequest struct {
+	Token string `json:"token"`
+}
+
+// PendingTeamInvite is an invitation addressed to the authenticated user
+type PendingTeamInvite struct {
+	ID        uint      `json:"id"`
+	TeamID    uint      `json:"team_id"`
+	TeamName  string    `json:"team_name"`
+	TeamSlug  string    `json:"team_slug"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// canInviteTeamRole reports whether a member can invite someone with a role.
+// Owners can invite managers and members, managers only members. Nobody is
+// invited as an owner; ownership is granted to existing members.
+func canInviteTeamRole(member *models.TeamMember, role string) bool {
+	switch member.Role {
+	case models.TeamRoleOwner:
+		return role == models.TeamRoleManager || role == models.TeamRoleMember
+	case models.TeamRoleManager:
+		return role == models.TeamRoleMember
+	}
+	return false
+}
+
+// InviteTeamMember invites an email address to join a team. The invitee is
+// emailed a token to accept it with, and the response is the same whether or
+// not the address has an account.
+func InviteTeamMember(db *gorm.DB, mail mailer.Mailer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		team, member, err := teamMembership(db, r, userID)
+		if err != nil {
+			respondTeamError(w, err)
+			return
+		}
+
+		var req TeamMemberRequest
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		email := strings.TrimSpace(strings.ToLower(req.Email))
+		if !strings.Contains(email, "@") {
+			utils.RespondError(w, http.StatusBadRequest, "A valid email is required")
+			return
+		}
+		if req.Role == "" {
+			req.Role = models.TeamRoleMember
+		}
+		if req.Role != models.TeamRoleManager && req.Role != models.TeamRoleMember {
+			utils.RespondError(w, http.StatusBadRequest, "Role must be manager or member")
+			return
+		}
+		if !canInviteTeamRole(member, req.Role) {
+			utils.RespondError(w, http.StatusForbidden, "You can't invite members with this role")
+			return
+		}
+
+		token, tokenHash, err := confirmation.NewToken()
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to invite team member")
+			return
+		}
+
+		// Re-inviting refreshes the role and expiry of a pending invitation and
+		// replaces its token
+		invite := models.TeamInvite{
+			TeamID:    team.ID,
+			Email:     email,
+			Role:      req.Role,
+			InvitedBy: userID,
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(teamInviteTTL),
+		}
+		err = db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "team_id"}, {Name: "email"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role", "invited_by", "token_hash", "expires_at"}),
+		}).Create(&invite).Error
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to invite team member")
+			return
+		}
+
+		// The token only reaches whoever controls the address, since
+		// registering with an email doesn't prove it
+		var inviter models.User
+		db.First(&inviter, userID)
+		err = mail.Send(r.Context(), mailer.Message{
+			To:      email,
+			Subject: fmt.Sprintf("You're invited to join %s", team.Name),
+			Body: fmt.Sprintf("Hi,\n\n%s invited you to join the team %s as a %s.\n\n"+
+				"Sign in with this email address and accept the invitation with this code:\n\n%s\n\n"+
+				"It expires in %d days. If you don't want to join, you can ignore this email.\n",
+				inviter.Name, team.Name, invite.Role, token, int(teamInviteTTL.Hours()/24)),
+		})
+		if err != nil {
+			log.Printf("Failed to send team invitation %d: %v", invite.ID, err)
+		}
+
+		utils.RespondSuccessWithMessage(w, teamInviteSent)
+	}
+}
+
+// ListTeamInvites lists a team's pending invitations (managers and owners)
+func ListTeamInvites(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		team, member, err := teamMembership(db, r, userID)
+		if err != nil {
+			respondTeamError(w, err)
+			return
+		}
+		if !hasTeamRole(member, models.TeamRoleManager) {
+			utils.RespondError(w, http.StatusForbidden, "Only team managers can view invitations")
+			return
+		}
+
+		invites := make([]models.TeamInvite, 0)
+		err = db.Where("team_id = ? AND expires_at > ?", team.ID, time.Now()).Order("created_at").Find(&invites).Error
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load invitations")
+			return
+		}
+
+		utils.RespondSuccess(w, invites)
+	}
+}
+
+// RevokeTeamInvite cancels a pending invitation. Managers can revoke member
+// invitations; owners can revoke any.
+func RevokeTeamInvite(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		team, member, err := teamMembership(db, r, userID)
+		if err != nil {
+			respondTeamError(w, err)
+			return
+		}
+
+		inviteID, err := strconv.ParseUint(chi.URLParam(r, "inviteID"), 10, 64)
+		if err != nil {
+			utils.RespondError(w, http.StatusNotFound, "Invitation not found")
+			return
+		}
+
+		var invite models.TeamInvite
+		if err := db.Where("id = ? AND team_id = ?", inviteID, team.ID).First(&invite).Error; err != nil {
+			utils.RespondError(w, http.StatusNotFound, "Invitation not found")
+			return
+		}
+		if !canInviteTeamRole(member, invite.Role) {
+			utils.RespondError(w, http.StatusForbidden, "You can't revoke this invitation")
+			return
+		}
+
+		if err := db.Delete(&invite).Error; err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to revoke invitation")
+			return
+		}
+
+		utils.RespondSuccessWithMessage(w, "Invitation revoked")
+	}
+}
+
+// ListMyTeamInvites lists the pending invitations addressed to the
+// authenticated user's email
+func ListMyTeamInvites(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, userID).Error; err != nil {
+			utils.RespondError(w, http.StatusNotFound, "User not found")
+			return
+		}
+
+		invites := make([]PendingTeamInvite, 0)
+		err := db.Model(&models.TeamInvite{}).
+			Select("team_invites.id, team_invites.team_id, teams.name AS team_name, teams.slug AS team_slug, team_invites.role, team_invites.expires_at, team_invites.created_at").
+			Joins("JOIN teams ON teams.id = team_invites.team_id AND teams.deleted_at IS NULL").
+			Where("team_invites.email = ? AND team_invites.expires_at > ?", strings.ToLower(user.Email), time.Now()).
+			Order("team_invites.created_at").
+			Scan(&invites).Error
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load invitations")
+			return
+		}
+
+		utils.RespondSuccess(w, invites)
+	}
+}
+
+// findMyTeamInvite loads a pending invitation addressed to the user
+func findMyTeamInvite(db *gorm.DB, r *http.Request, user *models.User) (*models.TeamInvite, error) {
+	inviteID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var invite models.TeamInvite
+	err = db.Where("id = ? AND email = ? AND expires_at > ?", inviteID, strings.ToLower(user.Email), time.Now()).First(&invite).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// AcceptTeamInvite joins the team of an invitation addressed to the
+// authenticated user, with the invited role. The invitation's emailed token is
+// required, so registering with someone else's address isn't enough.
+func AcceptTeamInvite(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, userID).Error; err != nil {
+			utils.RespondError(w, http.StatusNotFound, "User not found")
+			return
+		}
+
+		var req AcceptTeamInviteRequest
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if req.Token == "" {
+			utils.RespondError(w, http.StatusBadRequest, "The invitation code is required")
+			return
+		}
+
+		invite, err := findMyTeamInvite(db, r, &user)
+		if err != nil {
+			utils.RespondError(w, http.StatusNotFound, "Invitation not found")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(invite.TokenHash), []byte(confirmation.HashToken(req.Token))) != 1 {
+			utils.RespondError(w, http.StatusForbidden, "Invalid invitation code")
+			return
+		}
+
+		var team models.Team
+		if err := db.First(&team, invite.TeamID).Error; err != nil {
+			utils.RespondError(w, http.StatusNotFound, "Team not found")
+			return
+		}
+
+		// Existing members keep their role
+		joined := models.TeamMember{TeamID: team.ID, UserID: user.ID, Role: invite.Role}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			err := tx.Where("team_id = ? AND user_id = ?", team.ID, user.ID).
+				Attrs(joined).
+				FirstOrCreate(&joined).Error
+			if err != nil {
+				return err
+			}
+			// Tokens are single use, so a concurrent accept may have claimed it
+			result := tx.Where("token_hash = ?", invite.TokenHash).Delete(invite)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+			return nil
+		})
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.RespondError(w, http.StatusNotFound, "Invitation not found")
+			return
+		}
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to accept invitation")
+			return
+		}
+
+		utils.RespondSuccess(w, TeamSummary{Team: team, Role: joined.Role})
+	}
+}
+
+// DeclineTeamInvite declines an invitation addressed to the authenticated user
+func DeclineTeamInvite(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, userID).Error; err != nil {
+			utils.RespondError(w, http.StatusNotFound, "User not found")
+			return
+		}
+
+		invite, err := findMyTeamInvite(db, r, &user)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				utils.RespondError(w, http.StatusNotFound, "Invitation not found")
+				return
+			}
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load invitation")
+			return
+		}
+
+		if err := db.Delete(invite).Error; err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to decline invitation")
+			return
+		}
+
+		utils.RespondSuccessWithMessage(w, "Invitation declined")
+	}
+}
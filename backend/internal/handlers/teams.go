@@ -0,0 +1,598 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/snapshots"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
+	"github.com/go-chi/chi/v5"
+	"gorm.io/gorm"
+)
+
+// Top contributor list sizes
+const (
+	defaultTopContributors = 5
+	maxTopContributors     = 25
+)
+
+// teamRoleLevels orders roles by privilege
+var teamRoleLevels = map[string]int{
+	models.TeamRoleMember:  1,
+	models.TeamRoleManager: 2,
+	models.TeamRoleOwner:   3,
+}
+
+// slugSeparators matches runs of characters not allowed in a slug
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+var errNotTeamMember = errors.New("not a member of this team")
+
+// TeamRequest represents the request body for creating or updating a team
+type TeamRequest struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+}
+
+// TeamMemberRequest represents the request body for inviting or updating a member
+type TeamMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// TeamSummary is a team along with the authenticated user's role in it
+type TeamSummary struct {
+	models.Team
+	Role string `json:"role"`
+}
+
+// TeamMemberInfo describes a member of a team. Email is only set on the
+// viewer's own entry.
+type TeamMemberInfo struct {
+	UserID      uint      `json:"user_id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email,omitempty"`
+	GithubLogin string    `json:"github_login,omitempty"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// TeamContributor is a member's value for a single metric
+type TeamContributor struct {
+	UserID      uint   `json:"user_id"`
+	Name        string `json:"name"`
+	GithubLogin string `json:"github_login"`
+	Value       int    `json:"value"`
+}
+
+// TeamStats aggregates the GitHub statistics of a team's members
+type TeamStats struct {
+	Window           string                       `json:"window"`
+	Members          int                          `json:"members"`
+	MembersWithStats int                          `json:"members_with_stats"`
+	Totals           map[string]int               `json:"totals"`
+	Averages         map[string]float64           `json:"averages"`
+	RankDistribution map[string]int               `json:"rank_distribution"`
+	TopContributors  map[string][]TeamContributor `json:"top_contributors"`
+}
+
+// teamMembership loads a team and the user's membership in it
+func teamMembership(db *gorm.DB, r *http.Request, userID uint) (*models.Team, *models.TeamMember, error) {
+	teamID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	var team models.Team
+	if err := db.First(&team, teamID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var member models.TeamMember
+	if err := db.Where("team_id = ? AND user_id = ?", team.ID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errNotTeamMember
+		}
+		return nil, nil, err
+	}
+	return &team, &member, nil
+}
+
+// respondTeamError responds to an error from teamMembership. Non-members get
+// a 404 so team existence isn't revealed.
+func respondTeamError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, errNotTeamMember) {
+		utils.RespondError(w, http.StatusNotFound, "Team not found")
+		return
+	}
+	utils.RespondError(w, http.StatusInternalServerError, "Failed to retrieve team")
+}
+
+// hasTeamRole reports whether a member's role is at least the given role
+func hasTeamRole(member *models.TeamMember, role string) bool {
+	return teamRoleLevels[member.Role] >= teamRoleLevels[role]
+}
+
+// normalizeSlug returns a team slug from a requested slug or the team name
+func normalizeSlug(slug, name string) string {
+	if slug == "" {
+		slug = name
+	}
+	return strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(slug), "-"), "-")
+}
+
+// ListTeams lists the teams the authenticated user belongs to
+func ListTeams(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		teams := make([]TeamSummary, 0)
+		err := db.Model(&models.Team{}).
+			Select("teams.*, team_members.role").
+			Joins("JOIN team_members ON team_members.team_id = teams.id").
+			Where("team_members.user_id = ?", userID).
+			Order("teams.name").
+			Scan(&teams).Error
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load teams")
+			return
+		}
+
+		utils.RespondSuccess(w, teams)
+	}
+}
+
+// CreateTeam creates a team owned by the authenticated user
+func CreateTeam(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		var req TeamRequest
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		team := models.Team{
+			Name:        strings.TrimSpace(req.Name),
+			Slug:        normalizeSlug(req.Slug, req.Name),
+			Kind:        req.Kind,
+			Description: strings.TrimSpace(req.Description),
+		}
+		if team.Name == "" {
+			utils.RespondError(w, http.StatusBadRequest, "Team name is required")
+			return
+		}
+		if !handlePattern.MatchString(team.Slug) {
+			utils.RespondError(w, http.StatusBadRequest, "Slug must be 3-32 characters of lowercase letters, numbers and hyphens")
+			return
+		}
+		if team.Kind == "" {
+			team.Kind = models.TeamKindTeam
+		}
+		if team.Kind != models.TeamKindTeam && team.Kind != models.TeamKindOrganization {
+			utils.RespondError(w, http.StatusBadRequest, "Kind must be team or organization")
+			return
+		}
+
+		var existing models.Team
+		if err := db.Unscoped().Where("slug = ?", team.Slug).First(&existing).Error; err == nil {
+			utils.RespondError(w, http.StatusConflict, "Slug already taken")
+			return
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&team).Error; err != nil {
+				return err
+			}
+			return tx.Create(&models.TeamMember{TeamID: team.ID, UserID: userID, Role: models.TeamRoleOwner}).Error
+		})
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to create team")
+			return
+		}
+
+		utils.RespondSuccess(w, TeamSummary{Team: team, Role: models.TeamRoleOwner})
+	}
+}
+
+// GetTeam returns a team and its members
+func GetTeam(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		team, member, err := teamMembership(db, r, userID)
+		if err != nil {
+			respondTeamError(w, err)
+			return
+		}
+
+		members := make([]TeamMemberInfo, 0)
+		err = db.Model(&models.TeamMember{}).
+			Select("users.id AS user_id, users.name, CASE WHEN users.id = ? THEN users.email ELSE '' END AS email, users.github_username AS github_login, team_members.role, team_members.created_at AS joined_at", userID).
+			Joins("JOIN users ON users.id = team_members.user_id AND users.deleted_at IS NULL").
+			Where("team_members.team_id = ?", team.ID).
+			Order("users.name").
+			Scan(&members).Error
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load team members")
+			return
+		}
+
+		utils.RespondSuccess(w, map[string]interface{}{
+			"team":    TeamSummary{Team: *team, Role: member.Role},
+			"members": members,
+		})
+	}
+}
+
+// UpdateTeam updates a team's name and description (managers and owners)
+func UpdateTeam(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		team, member, err := teamMembership(db, r, userID)
+		if err != nil {
+			respondTeamError(w, err)
+			return
+		}
+		if !hasTeamRole(member, models.TeamRoleManager) {
+			utils.RespondError(w, http.StatusForbidden, "Only team managers can update the team")
+			return
+		}
+
+		var req TeamRequest
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		// Update fields if provided
+		if req.Name != "" {
+			team.Name = strings.TrimSpace(req.Name)
+		}
+		if req.Description != "" {
+			team.Description = strings.TrimSpace(req.Description)
+		}
+
+		if err := db.Save(team).Error; err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to update team")
+			return
+		}
+
+		utils.RespondSuccess(w, TeamSummary{Team: *team, Role: member.Role})
+	}
+}
+
+// DeleteTeam deletes a team (owners only)
+func DeleteTeam(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		team, member, err := teamMembership(db, r, userID)
+		if err != nil {
+			respondTeamError(w, err)
+			return
+		}
+		if !hasTeamRole(member, models.TeamRoleOwner) {
+			utils.RespondError(w, http.StatusForbidden, "Only team owners can delete the team")
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("team_id = ?", team.ID).Delete(&models.TeamMember{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("team_id = ?", team.ID).Delete(&models.TeamInvite{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(team).Error
+		})
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to delete team")
+			return
+		}
+
+		utils.RespondSuccessWithMessage(w, "Team deleted successfully")
+	}
+}
+
+// UpdateTeamMember changes a member's role (owners only)
+func UpdateTeamMember(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		team, member, err := teamMembership(db, r, userID)
+		if err != nil {
+			respondTeamError(w, err)
+			return
+		}
+		if !hasTeamRole(member, models.TeamRoleOwner) {
+			utils.RespondError(w, http.StatusForbidden, "Only team owners can change roles")
+			return
+		}
+
+		var req TeamMemberRequest
+		if err := utils.ParseJSON(r, &req); err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if _, ok := teamRoleLevels[req.Role]; !ok {
+			utils.RespondError(w, http.StatusBadRequest, "Role must be owner, manager or member")
+			return
+		}
+
+		target, err := findTeamMember(db, team.ID, chi.URLParam(r, "userID"))
+		if err != nil {
+			utils.RespondError(w, http.StatusNotFound, "Team member not found")
+			return
+		}
+
+		// Teams always keep an owner
+		if target.Role == models.TeamRoleOwner && req.Role != models.TeamRoleOwner {
+			last, err := lastTeamOwner(db, team.ID)
+			if err != nil {
+				utils.RespondError(w, http.StatusInternalServerError, "Failed to check team owners")
+				return
+			}
+			if last {
+				utils.RespondError(w, http.StatusBadRequest, "A team needs at least one owner")
+				return
+			}
+		}
+
+		target.Role = req.Role
+		if err := db.Save(target).Error; err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to update team member")
+			return
+		}
+
+		utils.RespondSuccess(w, target)
+	}
+}
+
+// RemoveTeamMember removes a member from a team. Members can remove
+// themselves; managers can remove members and owners can remove anyone.
+func RemoveTeamMember(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		team, member, err := teamMembership(db, r, userID)
+		if err != nil {
+			respondTeamError(w, err)
+			return
+		}
+
+		target, err := findTeamMember(db, team.ID, chi.URLParam(r, "userID"))
+		if err != nil {
+			utils.RespondError(w, http.StatusNotFound, "Team member not found")
+			return
+		}
+
+		if target.UserID != member.UserID && !canAssignTeamRole(member, target.Role) {
+			utils.RespondError(w, http.StatusForbidden, "You can't remove this member")
+			return
+		}
+		if target.Role == models.TeamRoleOwner {
+			last, err := lastTeamOwner(db, team.ID)
+			if err != nil {
+				utils.RespondError(w, http.StatusInternalServerError, "Failed to check team owners")
+				return
+			}
+			if last {
+				utils.RespondError(w, http.StatusBadRequest, "A team needs at least one owner")
+				return
+			}
+		}
+
+		if err := db.Delete(target).Error; err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to remove team member")
+			return
+		}
+
+		utils.RespondSuccessWithMessage(w, "Team member removed successfully")
+	}
+}
+
+// canAssignTeamRole reports whether a member can remove members with a role
+func canAssignTeamRole(member *models.TeamMember, role string) bool {
+	if member.Role == models.TeamRoleOwner {
+		return true
+	}
+	return member.Role == models.TeamRoleManager && role == models.TeamRoleMember
+}
+
+// findTeamMember loads a team's membership for a user ID path parameter
+func findTeamMember(db *gorm.DB, teamID uint, userIDParam string) (*models.TeamMember, error) {
+	targetID, err := strconv.ParseUint(userIDParam, 10, 64)
+	if err != nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var target models.TeamMember
+	if err := db.Where("team_id = ? AND user_id = ?", teamID, targetID).First(&target).Error; err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// lastTeamOwner reports whether a team has at most one owner whose account
+// isn't deleted
+func lastTeamOwner(db *gorm.DB, teamID uint) (bool, error) {
+	var owners int64
+	err := db.Model(&models.TeamMember{}).
+		Joins("JOIN users ON users.id = team_members.user_id AND users.deleted_at IS NULL").
+		Where("team_members.team_id = ? AND team_members.role = ?", teamID, models.TeamRoleOwner).
+		Count(&owners).Error
+	return owners <= 1, err
+}
+
+// GetTeamStats sums and averages the members' GitHub statistics for a
+// window, with the team's rank distribution and top contributors per metric.
+// Members who don't share their stats are counted but left out of the figures.
+func GetTeamStats(db *gorm.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.GetUserIDFromContext(r)
+		if !ok {
+			utils.RespondError(w, http.StatusUnauthorized, "User ID not found in context")
+			return
+		}
+
+		team, _, err := teamMembership(db, r, userID)
+		if err != nil {
+			respondTeamError(w, err)
+			return
+		}
+
+		query := r.URL.Query()
+		window := strings.ToLower(query.Get("window"))
+		if window == "" {
+			window = snapshots.WindowAll
+		}
+		windowStart, ok := snapshots.WindowStart(window, time.Now())
+		if !ok {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid window. Use all, week, month or year")
+			return
+		}
+
+		top := defaultTopContributors
+		if param := query.Get("top"); param != "" {
+			top, err = strconv.Atoi(param)
+			if err != nil || top < 1 || top > maxTopContributors {
+				utils.RespondError(w, http.StatusBadRequest, "top must be between 1 and 25")
+				return
+			}
+		}
+
+		// Load active members
+		var users []models.User
+		err = db.Joins("JOIN team_members ON team_members.user_id = users.id").
+			Where("team_members.team_id = ?", team.ID).
+			Order("users.id").
+			Find(&users).Error
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load team stats")
+			return
+		}
+
+		userIDs := make([]uint, 0, len(users))
+		for _, u := range users {
+			if u.SharesGithubStats() {
+				userIDs = append(userIDs, u.ID)
+			}
+		}
+
+		windowed, err := snapshots.ForWindow(db, userIDs, windowStart)
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to load team stats")
+			return
+		}
+
+		utils.RespondSuccess(w, aggregateTeamStats(window, users, windowed, top))
+	}
+}
+
+// aggregateTeamStats builds team statistics from members' windowed stats
+func aggregateTeamStats(window string, users []models.User, windowed map[uint]github.UserProfileStats, top int) TeamStats {
+	metrics := append([]string{"score"}, github.MetricNames()...)
+
+	result := TeamStats{
+		Window:           window,
+		Members:          len(users),
+		Totals:           make(map[string]int, len(metrics)),
+		Averages:         make(map[string]float64, len(metrics)),
+		RankDistribution: make(map[string]int, len(github.Ranks)),
+		TopContributors:  make(map[string][]TeamContributor, len(metrics)),
+	}
+	for _, rank := range github.Ranks {
+		result.RankDistribution[rank] = 0
+	}
+
+	for _, user := range users {
+		stats, ok := windowed[user.ID]
+		if !ok {
+			continue
+		}
+		result.MembersWithStats++
+
+		rank := github.CalculateRank(stats)
+		result.RankDistribution[rank.Rank]++
+
+		for _, metric := range metrics {
+			value := rank.Score
+			if metric != "score" {
+				value, _ = github.MetricValue(stats, metric)
+			}
+			result.Totals[metric] += value
+			result.TopContributors[metric] = append(result.TopContributors[metric], TeamContributor{
+				UserID:      user.ID,
+				Name:        user.Name,
+				GithubLogin: stats.Login,
+				Value:       value,
+			})
+		}
+	}
+
+	for _, metric := range metrics {
+		// Averages are over members with stats
+		if result.MembersWithStats > 0 {
+			average := float64(result.Totals[metric]) / float64(result.MembersWithStats)
+			result.Averages[metric] = math.Round(average*10) / 10
+		} else {
+			result.Averages[metric] = 0
+		}
+
+		contributors := result.TopContributors[metric]
+		sort.SliceStable(contributors, func(i, j int) bool {
+			return contributors[i].Value > contributors[j].Value
+		})
+		if len(contributors) > top {
+			contributors = contributors[:top]
+		}
+		if contributors == nil {
+			contributors = []TeamContributor{}
+		}
+		result.TopContributors[metric] = contributors
+	}
+
+	return result
+}
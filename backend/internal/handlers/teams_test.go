@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+)
+
+func TestCanInviteTeamRole(t *testing.T) {
+	tests := []struct {
+		inviter string
+		role    string
+		want    bool
+	}{
+		{models.TeamRoleOwner, models.TeamRoleOwner, false},
+		{models.TeamRoleOwner, models.TeamRoleManager, true},
+		{models.TeamRoleOwner, models.TeamRoleMember, true},
+		{models.TeamRoleManager, models.TeamRoleOwner, false},
+		{models.TeamRoleManager, models.TeamRoleManager, false},
+		{models.TeamRoleManager, models.TeamRoleMember, true},
+		{models.TeamRoleMember, models.TeamRoleMember, false},
+	}
+
+	for _, tt := range tests {
+		member := &models.TeamMember{Role: tt.inviter}
+		if got := canInviteTeamRole(member, tt.role); got != tt.want {
+			t.Errorf("%s inviting %s = %v, want %v", tt.inviter, tt.role, got, tt.want)
+		}
+	}
+}
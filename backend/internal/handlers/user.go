@@ -2,25 +2,56 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"regexp"
 	"strings"
 
+	"github.com/amilcar-vasquez/auth-service/backend/internal/achievements"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/confirmation"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/mailer"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/retention"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
 	"gorm.io/gorm"
 )
 
 type UserHandler struct {
-	DB *gorm.DB
+	DB     *gorm.DB
+	Mailer mailer.Mailer
 }
 
 // UpdateProfileRequest represents the profile update payload
 type UpdateProfileRequest struct {
 	Name   string `json:"name,omitempty"`
 	Avatar string `json:"avatar,omitempty"`
+
+	LeaderboardOptIn *bool `json:"leaderboard_opt_in,omitempty"`
+}
+
+// DeleteProfileRequest represents the account deletion payload
+type DeleteProfileRequest struct {
+	ConfirmationToken string `json:"confirmation_token"`
+}
+
+// UpdatePrivacyRequest represents the public profile settings payload
+type UpdatePrivacyRequest struct {
+	Handle          *string `json:"handle,omitempty"`
+	Public          *bool   `json:"public,omitempty"`
+	ShowEmail       *bool   `json:"show_email,omitempty"`
+	ShowGithubStats *bool   `json:"show_github_stats,omitempty"`
+	ShowRank        *bool   `json:"show_rank,omitempty"`
+
+	IncludePrivateContributions *bool `json:"include_private_contributions,omitempty"`
+	ShowPrivateContributions    *bool `json:"show_private_contributions,omitempty"`
 }
 
+// handlePattern matches lowercase public profile handles
+var handlePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,30}[a-z0-9]$`)
+
 // GetProfile retrieves the authenticated user's profile
 func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r)
@@ -74,6 +105,9 @@ func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	if req.Avatar != "" {
 		user.Avatar = strings.TrimSpace(req.Avatar)
 	}
+	if req.LeaderboardOptIn != nil {
+		user.LeaderboardOptIn = *req.LeaderboardOptIn
+	}
 
 	// Save changes
 	if err := h.DB.Save(&user).Error; err != nil {
@@ -84,7 +118,51 @@ func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	utils.RespondSuccess(w, user)
 }
 
-// DeleteProfile deletes the authenticated user's account
+// RequestAccountDeletion emails the authenticated user a confirmation token
+// for deleting their account
+func (h *UserHandler) RequestAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r)
+	if !ok {
+		utils.RespondError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		utils.RespondError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	// Don't send a code that can't be used yet
+	if err := retention.CheckTeamOwnership(h.DB, user.ID); err != nil {
+		respondDeletionError(w, err)
+		return
+	}
+
+	token, err := confirmation.Issue(h.DB, user.ID, models.ConfirmDeleteAccount, confirmation.DefaultTTL)
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to create confirmation token")
+		return
+	}
+
+	err = h.Mailer.Send(r.Context(), mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your account deletion",
+		Body: fmt.Sprintf("Hi %s,\n\nUse this code to confirm deleting your account:\n\n%s\n\n"+
+			"It expires in %d minutes. If you didn't ask to delete your account, change your password.\n",
+			user.Name, token, int(confirmation.DefaultTTL.Minutes())),
+	})
+	if err != nil {
+		log.Printf("Failed to send deletion confirmation to user %d: %v", user.ID, err)
+		utils.RespondError(w, http.StatusBadGateway, "Failed to send confirmation email")
+		return
+	}
+
+	utils.RespondSuccessWithMessage(w, "We sent a confirmation code to your email")
+}
+
+// DeleteProfile deletes the authenticated user's account. It requires recent
+// authentication and the confirmation token from RequestAccountDeletion.
 func (h *UserHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r)
 	if !ok {
@@ -92,11 +170,133 @@ func (h *UserHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Soft delete the user
-	if err := h.DB.Delete(&models.User{}, userID).Error; err != nil {
+	var req DeleteProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Use the token and soft delete the user together; the account can be
+	// restored until it is purged
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := confirmation.Consume(tx, userID, models.ConfirmDeleteAccount, strings.TrimSpace(req.ConfirmationToken)); err != nil {
+			return err
+		}
+		return retention.SoftDelete(tx, userID)
+	})
+	if err != nil {
+		respondDeletionError(w, err)
+		return
+	}
+
+	days := int(retention.GracePeriod().Hours() / 24)
+	utils.RespondSuccessWithMessage(w, fmt.Sprintf("Account deleted. You can restore it within %d days.", days))
+}
+
+// respondDeletionError writes the response for a failed account deletion
+func respondDeletionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, confirmation.ErrInvalidToken):
+		utils.RespondError(w, http.StatusBadRequest, "Invalid or expired confirmation token")
+	case errors.Is(err, retention.ErrSoleTeamOwner):
+		utils.RespondError(w, http.StatusConflict, "You are the only owner of a team with other members. Make another member an owner or delete the team first.")
+	default:
 		utils.RespondError(w, http.StatusInternalServerError, "Failed to delete account")
+	}
+}
+
+// UpdatePrivacy updates the authenticated user's public handle and privacy settings
+func (h *UserHandler) UpdatePrivacy(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r)
+	if !ok {
+		utils.RespondError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	var req UpdatePrivacyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.RespondError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	// Retrieve user
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.RespondError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to retrieve user")
+		return
+	}
+
+	// Validate and apply the handle; an empty handle removes it
+	if req.Handle != nil {
+		handle := strings.TrimSpace(strings.ToLower(*req.Handle))
+		if handle == "" {
+			user.Handle = nil
+		} else {
+			if !handlePattern.MatchString(handle) {
+				utils.RespondError(w, http.StatusBadRequest, "Handle must be 3-32 characters of lowercase letters, numbers and hyphens")
+				return
+			}
+
+			var existing models.User
+			if err := h.DB.Unscoped().Where("handle = ? AND id <> ?", handle, user.ID).First(&existing).Error; err == nil {
+				utils.RespondError(w, http.StatusConflict, "Handle already taken")
+				return
+			}
+			user.Handle = &handle
+		}
+	}
+
+	// Update settings if provided
+	if req.Public != nil {
+		user.Privacy.Public = *req.Public
+	}
+	if req.ShowEmail != nil {
+		user.Privacy.ShowEmail = *req.ShowEmail
+	}
+	if req.ShowGithubStats != nil {
+		user.Privacy.ShowGithubStats = *req.ShowGithubStats
+	}
+	if req.ShowRank != nil {
+		user.Privacy.ShowRank = *req.ShowRank
+	}
+	if req.IncludePrivateContributions != nil {
+		user.Privacy.IncludePrivateContributions = *req.IncludePrivateContributions
+	}
+	if req.ShowPrivateContributions != nil {
+		user.Privacy.ShowPrivateContributions = *req.ShowPrivateContributions
+	}
+
+	if user.Privacy.Public && user.Handle == nil {
+		utils.RespondError(w, http.StatusBadRequest, "A handle is required to make your profile public")
+		return
+	}
+
+	// Save changes
+	if err := h.DB.Save(&user).Error; err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to update privacy settings")
+		return
+	}
+
+	utils.RespondSuccess(w, user)
+}
+
+// GetAchievements lists the authenticated user's achievements and progress
+func (h *UserHandler) GetAchievements(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r)
+	if !ok {
+		utils.RespondError(w, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	list, err := achievements.List(h.DB, userID)
+	if err != nil {
+		utils.RespondError(w, http.StatusInternalServerError, "Failed to load achievements")
 		return
 	}
 
-	utils.RespondSuccessWithMessage(w, "Account deleted successfully")
+	utils.RespondSuccess(w, list)
 }
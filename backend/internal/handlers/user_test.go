@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/confirmation"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/retention"
+)
+
+func TestRespondDeletionError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{confirmation.ErrInvalidToken, http.StatusBadRequest},
+		{fmt.Errorf("soft delete: %w", retention.ErrSoleTeamOwner), http.StatusConflict},
+		{errors.New("connection reset"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		respondDeletionError(rec, tt.err)
+		if rec.Code != tt.status {
+			t.Errorf("%v: status = %d, want %d", tt.err, rec.Code, tt.status)
+		}
+	}
+}
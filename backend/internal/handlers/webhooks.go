@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/providers"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/refresh"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/snapshots"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxWebhookPayload matches GitHub's 25 MB payload cap
+const maxWebhookPayload = 25 << 20
+
+// webhookEvents are the events that change the stats we rank on
+var webhookEvents = map[string]bool{
+	"push":                true,
+	"pull_request":        true,
+	"issues":              true,
+	"pull_request_review": true,
+}
+
+// webhookUser is a GitHub account in a webhook payload
+type webhookUser struct {
+	Login string `json:"login"`
+}
+
+// webhookPayload holds the accounts involved in a webhook event
+type webhookPayload struct {
+	Sender      *webhookUser `json:"sender"`
+	PullRequest *struct {
+		User webhookUser `json:"user"`
+	} `json:"pull_request"`
+	Issue *struct {
+		User webhookUser `json:"user"`
+	} `json:"issue"`
+	Review *struct {
+		User webhookUser `json:"user"`
+	} `json:"review"`
+}
+
+// logins returns the lowercased logins of the accounts involved in the event
+func (p webhookPayload) logins() []string {
+	var users []webhookUser
+	if p.Sender != nil {
+		users = append(users, *p.Sender)
+	}
+	if p.PullRequest != nil {
+		users = append(users, p.PullRequest.User)
+	}
+	if p.Issue != nil {
+		users = append(users, p.Issue.User)
+	}
+	if p.Review != nil {
+		users = append(users, p.Review.User)
+	}
+
+	seen := make(map[string]bool)
+	logins := make([]string, 0, len(users))
+	for _, u := range users {
+		login := strings.ToLower(u.Login)
+		if login != "" && !seen[login] {
+			seen[login] = true
+			logins = append(logins, login)
+		}
+	}
+	return logins
+}
+
+// GithubWebhook receives GitHub webhook deliveries and refreshes the
+// snapshots of connected users involved in new activity
+func GithubWebhook(db *gorm.DB, secret string, scheduler *refresh.Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if secret == "" {
+			utils.RespondError(w, http.StatusServiceUnavailable, "Webhooks are not configured")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookPayload))
+		if err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		// Verify the payload was signed with the shared secret
+		if !validWebhookSignature(secret, body, r.Header.Get("X-Hub-Signature-256")) {
+			utils.RespondError(w, http.StatusUnauthorized, "Invalid webhook signature")
+			return
+		}
+
+		event := r.Header.Get("X-GitHub-Event")
+		if event == "ping" {
+			utils.RespondSuccessWithMessage(w, "pong")
+			return
+		}
+		if !webhookEvents[event] {
+			utils.RespondSuccessWithMessage(w, "Event ignored")
+			return
+		}
+
+		deliveryID := r.Header.Get("X-GitHub-Delivery")
+		if deliveryID == "" {
+			utils.RespondError(w, http.StatusBadRequest, "Missing delivery ID")
+			return
+		}
+
+		var payload webhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			utils.RespondError(w, http.StatusBadRequest, "Invalid webhook payload")
+			return
+		}
+
+		// Enterprise Server deliveries name their host; others come from github.com
+		host := github.StripHost(r.Header.Get("X-GitHub-Enterprise-Host"))
+		if host == "" {
+			host = github.DefaultHost
+		}
+
+		userIDs, err := webhookUserIDs(db, host, payload.logins())
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to look up users")
+			return
+		}
+
+		// Record the delivery and mark snapshots dirty together, so a failed
+		// delivery can be redelivered
+		duplicate := false
+		err = db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.WebhookDelivery{ID: deliveryID, Event: event})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				duplicate = true
+				return nil
+			}
+			return snapshots.MarkDirty(tx, userIDs)
+		})
+		if err != nil {
+			utils.RespondError(w, http.StatusInternalServerError, "Failed to process webhook")
+			return
+		}
+		if duplicate {
+			utils.RespondSuccessWithMessage(w, "Delivery already processed")
+			return
+		}
+
+		for _, id := range userIDs {
+			scheduler.Schedule(id)
+		}
+
+		utils.RespondSuccess(w, map[string]interface{}{
+			"event":     event,
+			"scheduled": len(userIDs),
+		})
+	}
+}
+
+// validWebhookSignature checks an X-Hub-Signature-256 header against the body
+func validWebhookSignature(secret string, body []byte, header string) bool {
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	received, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(received, mac.Sum(nil))
+}
+
+// webhookUserIDs returns the users with a GitHub account on the host matching
+// any of the logins
+func webhookUserIDs(db *gorm.DB, host string, logins []string) ([]uint, error) {
+	if len(logins) == 0 {
+		return nil, nil
+	}
+
+	// The primary account stores github.com as an empty host
+	primary := db.Model(&models.User{}).Where("LOWER(github_username) IN ? AND github_token <> ''", logins)
+	if host == github.DefaultHost {
+		primary = primary.Where("(github_host IS NULL OR github_host = '')")
+	} else {
+		primary = primary.Where("github_host = ?", host)
+	}
+
+	var ids []uint
+	if err := primary.Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	var additional []uint
+	err := db.Model(&models.GithubAccount{}).
+		Where("provider = ? AND host = ? AND LOWER(username) IN ?", providers.GitHub, host, logins).
+		Distinct().Pluck("user_id", &additional).Error
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	for _, id := range additional {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,122 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Message is an email to a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// LogMailer writes emails to the log instead of sending them. It is meant
+// for development, when no SMTP server is configured.
+type LogMailer struct {
+	// LogBodies also logs message bodies, which hold confirmation codes and
+	// invitation tokens
+	LogBodies bool
+}
+
+// Send logs the message's recipient and subject, and its body when enabled
+func (m LogMailer) Send(ctx context.Context, message Message) error {
+	if m.LogBodies {
+		log.Printf("Email to %s: %s\n%s", message.To, message.Subject, message.Body)
+		return nil
+	}
+	log.Printf("Email to %s: %s", message.To, message.Subject)
+	return nil
+}
+
+// SMTPMailer sends emails through an SMTP server
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send delivers the message as plain text, upgrading to TLS when the server
+// supports it and authenticating when a username is set. The connection is
+// bounded by the context, so a stalled server can't hold up the caller.
+func (m SMTPMailer) Send(ctx context.Context, message Message) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.Host, m.Port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Unblock reads and writes as soon as the context is canceled
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := m.deliver(conn, message); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+	return nil
+}
+
+// deliver runs the SMTP conversation for one message over a connection
+func (m SMTPMailer) deliver(conn net.Conn, message Message) error {
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+			return err
+		}
+	}
+	if m.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return err
+	}
+
+	// Keep header values on one line
+	clean := strings.NewReplacer("\r", "", "\n", "")
+	body := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s",
+		clean.Replace(m.From), clean.Replace(message.To), clean.Replace(message.Subject), message.Body)
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
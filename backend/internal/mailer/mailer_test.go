@@ -0,0 +1,142 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startSMTPServer accepts one connection and answers it with handle
+func startSMTPServer(t *testing.T, handle func(conn net.Conn)) (host, port string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handle(conn)
+	}()
+
+	host, port, _ = net.SplitHostPort(listener.Addr().String())
+	return host, port
+}
+
+func TestLogMailerSend(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	message := Message{To: "alice@example.com", Subject: "Confirm", Body: "Your code is 123456"}
+	if err := (LogMailer{}).Send(context.Background(), message); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if !strings.Contains(logged.String(), "alice@example.com: Confirm") || strings.Contains(logged.String(), "123456") {
+		t.Errorf("logged %q", logged.String())
+	}
+
+	logged.Reset()
+	if err := (LogMailer{LogBodies: true}).Send(context.Background(), message); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if !strings.Contains(logged.String(), "123456") {
+		t.Errorf("logged %q, want the body", logged.String())
+	}
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	received := make(chan string, 1)
+	host, port := startSMTPServer(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 localhost ready")
+		var data strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL"), strings.HasPrefix(command, "RCPT"):
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 Go ahead")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 Queued")
+			case command == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("502 Unsupported")
+			}
+		}
+	})
+
+	mailer := SMTPMailer{Host: host, Port: port, From: "no-reply@example.com"}
+	err := mailer.Send(context.Background(), Message{To: "alice@example.com", Subject: "Hello\r\nBcc: eve@example.com", Body: "Hi Alice"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	data := <-received
+	if !strings.Contains(data, "Subject: HelloBcc: eve@example.com\r\n") || !strings.Contains(data, "Hi Alice") {
+		t.Errorf("message = %q", data)
+	}
+}
+
+func TestSMTPMailerSendRespectsDeadline(t *testing.T) {
+	// The server accepts the connection but never greets
+	done := make(chan struct{})
+	host, port := startSMTPServer(t, func(conn net.Conn) { <-done })
+	defer close(done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := SMTPMailer{Host: host, Port: port, From: "no-reply@example.com"}.Send(ctx, Message{To: "alice@example.com"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %v", elapsed)
+	}
+}
+
+func TestSMTPMailerSendCanceled(t *testing.T) {
+	done := make(chan struct{})
+	host, port := startSMTPServer(t, func(conn net.Conn) { <-done })
+	defer close(done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	err := SMTPMailer{Host: host, Port: port, From: "no-reply@example.com"}.Send(ctx, Message{To: "alice@example.com"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
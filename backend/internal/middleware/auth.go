@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
 )
@@ -13,6 +14,9 @@ type contextKey string
 
 const UserIDKey contextKey = "userID"
 
+// AuthTimeKey holds when the user last entered their password
+const AuthTimeKey contextKey = "authTime"
+
 // AuthMiddleware validates JWT tokens and protects routes
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -39,8 +43,11 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Add user ID to request context
+		// Add user ID and authentication time to request context
 		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+		if claims.AuthTime != nil {
+			ctx = context.WithValue(ctx, AuthTimeKey, claims.AuthTime.Time)
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -50,3 +57,9 @@ func GetUserIDFromContext(r *http.Request) (uint, bool) {
 	userID, ok := r.Context().Value(UserIDKey).(uint)
 	return userID, ok
 }
+
+// GetAuthTimeFromContext extracts when the user last entered their password
+func GetAuthTimeFromContext(r *http.Request) (time.Time, bool) {
+	authTime, ok := r.Context().Value(AuthTimeKey).(time.Time)
+	return authTime, ok
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/utils"
+)
+
+// DefaultSudoWindow is how long after entering their password a user may
+// perform sensitive actions
+const DefaultSudoWindow = 10 * time.Minute
+
+// ReauthenticationRequired is the error code clients receive when they must
+// confirm the user's password before retrying
+const ReauthenticationRequired = "reauthentication_required"
+
+// sudoWindow is how recent authentication must be for RequireRecentAuth
+var sudoWindow = DefaultSudoWindow
+
+// SetSudoWindow sets how recent authentication must be for sensitive actions
+func SetSudoWindow(window time.Duration) {
+	sudoWindow = window
+}
+
+// RequireRecentAuth protects sensitive routes ("sudo mode"). It must run after
+// AuthMiddleware and rejects tokens whose auth_time is older than the sudo
+// window, or missing, until the user re-authenticates.
+func RequireRecentAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authTime, ok := GetAuthTimeFromContext(r)
+		if !ok || time.Since(authTime) > sudoWindow {
+			utils.RespondErrorCode(w, http.StatusForbidden, ReauthenticationRequired, "Please confirm your password to continue")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Achievement is a badge awarded to a user when a snapshot of their GitHub
+// statistics first met an achievement rule. The values it was awarded for are
+// copied from the snapshot, which is overwritten by later refreshes that day.
+type Achievement struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_user_achievement" json:"user_id"`
+	RuleID     string    `gorm:"not null;uniqueIndex:idx_user_achievement" json:"rule_id"`
+	SnapshotID uint      `json:"snapshot_id"`
+	AwardedAt  time.Time `gorm:"not null" json:"awarded_at"`
+	Value      int       `gorm:"not null;default:0" json:"value"` // The rule's metric when awarded
+	Score      int       `gorm:"not null;default:0" json:"score"`
+	Rank       string    `json:"rank"`
+}
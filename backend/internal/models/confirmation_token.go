@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Confirmation token purposes
+const (
+	ConfirmDeleteAccount = "delete_account"
+)
+
+// ConfirmationToken is a single-use code emailed to a user to confirm a
+// sensitive action. Only a hash of the code is stored.
+type ConfirmationToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	Purpose   string     `gorm:"not null" json:"purpose"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
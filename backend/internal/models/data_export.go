@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Data export statuses
+const (
+	DataExportPending = "pending"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+)
+
+// DataExport is an archive of everything stored about a user, assembled in
+// the background and downloadable until it expires
+type DataExport struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"index;not null" json:"user_id"`
+	Status      string     `gorm:"not null;default:'pending'" json:"status"`
+	Error       string     `json:"error,omitempty"`
+	Path        string     `json:"-"` // Archive location on disk
+	Size        int64      `json:"size"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `gorm:"index" json:"expires_at,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// GithubAccount is an additional forge account connected to a user, such as
+// an account on a GitHub Enterprise Server, GitLab or Gitea instance
+type GithubAccount struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	Provider  string    `gorm:"not null;default:'github'" json:"provider"` // github, gitlab or gitea
+	Host      string    `gorm:"not null;default:'github.com'" json:"host"`
+	Username  string    `gorm:"not null" json:"username"`
+	Token     string    `json:"-"` // Never expose GitHub token in JSON
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// GithubSnapshot stores a point-in-time copy of a user's GitHub statistics.
+// Each user has at most one snapshot per day, holding that day's latest stats.
+type GithubSnapshot struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"index;not null;uniqueIndex:idx_github_snapshots_user_day" json:"user_id"`
+	Day          time.Time `gorm:"type:date;uniqueIndex:idx_github_snapshots_user_day" json:"day"`
+	Login        string    `json:"login"`
+	Commits      int       `json:"commits"`
+	PullRequests int       `json:"pull_requests"`
+	Issues       int       `json:"issues"`
+	Reviews      int       `json:"reviews"`
+	Stars        int       `json:"stars"`
+	Followers    int       `json:"followers"`
+	Score        int       `json:"score"`
+	Rank         string    `json:"rank"`
+	Stats        []byte    `gorm:"type:jsonb" json:"-"`                 // Full profile stats as returned by the GitHub client
+	Dirty        bool      `gorm:"not null;default:false" json:"dirty"` // Set when a webhook reports newer activity, cleared by the refresh
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+
+	// The part of the contribution counts from private and restricted
+	// contributions, counted with the user's consent. Anything shown to other
+	// users leaves them out.
+	PrivateCommits      int `gorm:"not null;default:0" json:"private_commits"`
+	PrivatePullRequests int `gorm:"not null;default:0" json:"private_pull_requests"`
+	PrivateIssues       int `gorm:"not null;default:0" json:"private_issues"`
+	PrivateReviews      int `gorm:"not null;default:0" json:"private_reviews"`
+}
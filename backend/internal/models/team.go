@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Team kinds
+const (
+	TeamKindTeam         = "team"
+	TeamKindOrganization = "organization"
+)
+
+// Team member roles, from most to least privileged
+const (
+	TeamRoleOwner   = "owner"
+	TeamRoleManager = "manager"
+	TeamRoleMember  = "member"
+)
+
+// Team groups users, such as a team or a whole organization, so their
+// GitHub statistics can be viewed together
+type Team struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Name        string         `gorm:"not null" json:"name"`
+	Slug        string         `gorm:"uniqueIndex;not null" json:"slug"`
+	Kind        string         `gorm:"not null;default:'team'" json:"kind"` // team or organization
+	Description string         `json:"description"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TeamMember is a user's membership and role in a team
+type TeamMember struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TeamID    uint      `gorm:"not null;uniqueIndex:idx_team_member" json:"team_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_team_member;index" json:"user_id"`
+	Role      string    `gorm:"not null;default:'member'" json:"role"` // owner, manager or member
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TeamInvite is an invitation for an email address to join a team. The
+// invitee only becomes a member once they accept it with the token emailed
+// to the address, since account emails aren't verified.
+type TeamInvite struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TeamID    uint      `gorm:"not null;uniqueIndex:idx_team_invite" json:"team_id"`
+	Email     string    `gorm:"not null;uniqueIndex:idx_team_invite;index" json:"email"`
+	Role      string    `gorm:"not null;default:'member'" json:"role"` // manager or member
+	InvitedBy uint      `gorm:"not null" json:"invited_by"`
+	TokenHash string    `gorm:"not null;default:''" json:"-"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -8,14 +8,42 @@ import (
 
 // User represents a user in the system
 type User struct {
-	ID             uint           `gorm:"primaryKey" json:"id"`
-	Name           string         `gorm:"not null" json:"name"`
-	Email          string         `gorm:"uniqueIndex;not null" json:"email"`
-	PasswordHash   string         `gorm:"not null" json:"-"` // Never expose password hash in JSON
-	Avatar         string         `json:"avatar,omitempty"`
-	GithubUsername string         `json:"github_username,omitempty"`
-	GithubToken    string         `json:"-"` // Never expose GitHub token in JSON
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	ID               uint            `gorm:"primaryKey" json:"id"`
+	Name             string          `gorm:"not null" json:"name"`
+	Email            string          `gorm:"uniqueIndex:idx_users_email_active,where:deleted_at IS NULL;not null" json:"email"`
+	PasswordHash     string          `gorm:"not null" json:"-"` // Never expose password hash in JSON
+	Avatar           string          `json:"avatar,omitempty"`
+	GithubUsername   string          `json:"github_username,omitempty"`
+	GithubToken      string          `json:"-"`                     // Never expose GitHub token in JSON
+	GithubHost       string          `json:"github_host,omitempty"` // Empty means github.com
+	LeaderboardOptIn bool            `gorm:"not null;default:false" json:"leaderboard_opt_in"`
+	Handle           *string         `gorm:"uniqueIndex" json:"handle,omitempty"` // Public profile slug
+	Privacy          PrivacySettings `gorm:"embedded;embeddedPrefix:privacy_" json:"privacy"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt  `gorm:"index" json:"-"`
+}
+
+// SharesGithubStats reports whether the user's GitHub stats are visible to
+// other users, on the leaderboard or their public profile
+func (u User) SharesGithubStats() bool {
+	return u.LeaderboardOptIn || (u.Privacy.Public && u.Privacy.ShowGithubStats)
+}
+
+// PrivacySettings controls what is visible on a user's public profile
+type PrivacySettings struct {
+	Public          bool `gorm:"not null;default:false" json:"public"`
+	ShowEmail       bool `gorm:"not null;default:false" json:"show_email"`
+	ShowGithubStats bool `gorm:"not null;default:false" json:"show_github_stats"`
+	ShowRank        bool `gorm:"not null;default:false" json:"show_rank"`
+	// IncludePrivateContributions counts private and restricted contributions
+	// in the user's own stats and rank
+	IncludePrivateContributions bool `gorm:"not null;default:false" json:"include_private_contributions"`
+	// ShowPrivateContributions shares those counts on the public profile and cards
+	ShowPrivateContributions bool `gorm:"not null;default:false" json:"show_private_contributions"`
+}
+
+// SharesPrivateContributions reports whether private contributions may be shown to others
+func (p PrivacySettings) SharesPrivateContributions() bool {
+	return p.IncludePrivateContributions && p.ShowPrivateContributions
 }
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// WebhookDelivery records a processed GitHub webhook delivery so redelivered
+// events are only handled once
+type WebhookDelivery struct {
+	ID        string    `gorm:"primaryKey" json:"id"` // X-GitHub-Delivery header
+	Event     string    `gorm:"not null" json:"event"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,90 @@
+package providers
+
+import (
+	"strings"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// PrimaryAccount returns the GitHub account set on the user's profile, if any
+func PrimaryAccount(user *models.User) (models.GithubAccount, bool) {
+	if user.GithubUsername == "" {
+		return models.GithubAccount{}, false
+	}
+	host := user.GithubHost
+	if host == "" {
+		host = github.DefaultHost
+	}
+	return models.GithubAccount{
+		UserID:   user.ID,
+		Provider: GitHub,
+		Host:     host,
+		Username: user.GithubUsername,
+		Token:    user.GithubToken,
+	}, true
+}
+
+// ConnectedAccounts returns the user's primary GitHub account followed by any
+// additional connected accounts. The primary account is not stored in the
+// github_accounts table and has an ID of zero. An account connected more than
+// once is only returned the first time, so its stats aren't combined twice.
+func ConnectedAccounts(db *gorm.DB, user *models.User) ([]models.GithubAccount, error) {
+	accounts := make([]models.GithubAccount, 0)
+
+	if primary, ok := PrimaryAccount(user); ok && primary.Token != "" {
+		accounts = append(accounts, primary)
+	}
+
+	var additional []models.GithubAccount
+	if err := db.Where("user_id = ?", user.ID).Order("id").Find(&additional).Error; err != nil {
+		return nil, err
+	}
+
+	return uniqueAccounts(append(accounts, additional...)), nil
+}
+
+// SameAccount reports whether two accounts are the same login on the same
+// instance, comparing normalized hosts and ignoring the case of logins
+func SameAccount(a, b models.GithubAccount) bool {
+	return accountProvider(a) == accountProvider(b) &&
+		accountHost(a) == accountHost(b) &&
+		strings.EqualFold(a.Username, b.Username)
+}
+
+// uniqueAccounts drops accounts that repeat an earlier one
+func uniqueAccounts(accounts []models.GithubAccount) []models.GithubAccount {
+	unique := make([]models.GithubAccount, 0, len(accounts))
+	for _, account := range accounts {
+		duplicate := false
+		for _, kept := range unique {
+			if SameAccount(kept, account) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			unique = append(unique, account)
+		}
+	}
+	return unique
+}
+
+// accountProvider returns an account's provider, which defaults to GitHub
+func accountProvider(account models.GithubAccount) string {
+	if account.Provider == "" {
+		return GitHub
+	}
+	return account.Provider
+}
+
+// accountHost returns an account's normalized host. Hosts that are no longer
+// allowed are still compared by their bare name.
+func accountHost(account models.GithubAccount) string {
+	host, err := NormalizeHost(accountProvider(account), account.Host)
+	if err != nil {
+		return github.StripHost(account.Host)
+	}
+	return host
+}
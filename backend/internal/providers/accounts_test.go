@@ -0,0 +1,48 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+)
+
+func TestSameAccount(t *testing.T) {
+	primary := models.GithubAccount{Provider: GitHub, Host: "github.com", Username: "Octocat"}
+
+	tests := []struct {
+		name    string
+		account models.GithubAccount
+		want    bool
+	}{
+		{"same", models.GithubAccount{Provider: GitHub, Host: "github.com", Username: "octocat"}, true},
+		{"default host", models.GithubAccount{Username: "OCTOCAT"}, true},
+		{"host URL", models.GithubAccount{Provider: GitHub, Host: "https://GitHub.com/", Username: "octocat"}, true},
+		{"other login", models.GithubAccount{Provider: GitHub, Host: "github.com", Username: "hubot"}, false},
+		{"other provider", models.GithubAccount{Provider: GitLab, Host: "gitlab.com", Username: "octocat"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := SameAccount(primary, tt.account); got != tt.want {
+			t.Errorf("%s: SameAccount = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueAccountsDropsRepeatedPrimary(t *testing.T) {
+	user := &models.User{ID: 1, GithubUsername: "octocat", GithubToken: "token"}
+	primary, ok := PrimaryAccount(user)
+	if !ok {
+		t.Fatal("no primary account")
+	}
+
+	accounts := uniqueAccounts([]models.GithubAccount{
+		primary,
+		{ID: 1, Provider: GitHub, Host: "github.com", Username: "OctoCat"},
+		{ID: 2, Provider: GitLab, Host: "gitlab.com", Username: "octocat"},
+		{ID: 3, Provider: GitLab, Host: "gitlab.com", Username: "Octocat"},
+	})
+
+	if len(accounts) != 2 || accounts[0].ID != 0 || accounts[1].ID != 2 {
+		t.Errorf("accounts = %+v", accounts)
+	}
+}
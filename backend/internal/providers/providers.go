@@ -0,0 +1,79 @@
+// Package providers selects the source of developer statistics for a
+// connected account, so ranks work for users on any supported forge.
+package providers
+
+import (
+	"errors"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/gitlab"
+)
+
+// Supported providers
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+	Gitea  = "gitea"
+)
+
+// Provider fetches a user's profile statistics from a forge, mapped onto the
+// metrics used for ranking
+type Provider = github.ProfileFetcher
+
+// Registry maps provider names to their implementations
+type Registry map[string]Provider
+
+// ErrUnknownProvider is returned for providers that are not registered
+var ErrUnknownProvider = errors.New("unknown provider")
+
+// ErrHostNotAllowed is returned for self-hosted instances that are not configured
+var ErrHostNotAllowed = errors.New("host is not an allowed instance for this provider")
+
+// allowedHosts are the self-hosted instances users may connect per provider.
+// Tokens are sent to the host, so arbitrary hosts are never allowed.
+var allowedHosts = map[string]map[string]bool{}
+
+// SetAllowedHosts sets the instances users may connect for a provider. GitHub
+// hosts are configured with github.SetEnterpriseHosts instead.
+func SetAllowedHosts(provider string, hosts []string) {
+	allowed := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		if normalized := github.StripHost(host); normalized != "" {
+			allowed[normalized] = true
+		}
+	}
+	allowedHosts[provider] = allowed
+}
+
+// Get returns the implementation of a provider; an empty name means GitHub
+func (r Registry) Get(name string) (Provider, error) {
+	if name == "" {
+		name = GitHub
+	}
+	provider, ok := r[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// NormalizeHost validates a host for a provider and returns it in bare
+// lowercase form. An empty host means the provider's public instance, where
+// one exists.
+func NormalizeHost(provider, host string) (string, error) {
+	switch provider {
+	case "", GitHub:
+		return github.NormalizeHost(host)
+	case GitLab, Gitea:
+		normalized := github.StripHost(host)
+		if provider == GitLab && (normalized == "" || normalized == gitlab.DefaultHost) {
+			return gitlab.DefaultHost, nil
+		}
+		if !allowedHosts[provider][normalized] {
+			return "", ErrHostNotAllowed
+		}
+		return normalized, nil
+	default:
+		return "", ErrUnknownProvider
+	}
+}
@@ -0,0 +1,170 @@
+// Package refresh updates users' stored GitHub snapshots in the background,
+// so stats stay current without the user opening their profile.
+package refresh
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/achievements"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/providers"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/snapshots"
+	"gorm.io/gorm"
+)
+
+// DefaultDelay batches bursts of activity, such as a push followed by a pull
+// request, into a single refresh
+const DefaultDelay = 30 * time.Second
+
+// Limits for background refreshes
+const (
+	maxConcurrent  = 4
+	refreshTimeout = 2 * time.Minute
+)
+
+// sweepInterval is how often dirty snapshots whose refresh was lost or
+// failed are queued again
+const sweepInterval = 15 * time.Minute
+
+// DeliveryTTL is how long webhook delivery IDs are kept to detect
+// redeliveries. GitHub only redelivers events from the last three days.
+const DeliveryTTL = 7 * 24 * time.Hour
+
+// Scheduler refreshes users' snapshots after a delay, running at most one
+// pending refresh per user
+type Scheduler struct {
+	db       *gorm.DB
+	registry providers.Registry
+	delay    time.Duration
+
+	mu      sync.Mutex
+	pending map[uint]bool
+	slots   chan struct{}
+}
+
+// NewScheduler creates a scheduler that refreshes snapshots using the registry
+func NewScheduler(db *gorm.DB, registry providers.Registry, delay time.Duration) *Scheduler {
+	return &Scheduler{
+		db:       db,
+		registry: registry,
+		delay:    delay,
+		pending:  make(map[uint]bool),
+		slots:    make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Schedule queues a refresh of the user's snapshot; it is a no-op if one is
+// already pending
+func (s *Scheduler) Schedule(userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending[userID] {
+		return
+	}
+	s.pending[userID] = true
+
+	time.AfterFunc(s.delay, func() {
+		s.mu.Lock()
+		delete(s.pending, userID)
+		s.mu.Unlock()
+
+		s.slots <- struct{}{}
+		defer func() { <-s.slots }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+
+		if err := s.Refresh(ctx, userID); err != nil {
+			log.Printf("Failed to refresh GitHub snapshot for user %d: %v", userID, err)
+		}
+	})
+}
+
+// Refresh fetches the user's year-to-date stats from every connected account
+// and stores a new snapshot
+func (s *Scheduler) Refresh(ctx context.Context, userID uint) error {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	accounts, err := providers.ConnectedAccounts(s.db, &user)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	period := github.DefaultTimeRange(time.Now())
+	fetched := make([]*github.UserProfileStats, 0, len(accounts))
+	for _, account := range accounts {
+		fetcher, err := s.registry.Get(account.Provider)
+		if err != nil {
+			return err
+		}
+
+		stats, err := fetcher.FetchUserProfile(ctx, account.Username, account.Token, github.FetchOptions{
+			Host:           account.Host,
+			Period:         period,
+			IncludePrivate: user.Privacy.IncludePrivateContributions,
+		})
+		if err != nil {
+			return err
+		}
+		fetched = append(fetched, stats)
+	}
+
+	stats := github.CombineStats(fetched)
+	snapshot, err := snapshots.Save(s.db, user.ID, stats, github.CalculateRank(*stats))
+	if err != nil {
+		return err
+	}
+
+	_, err = achievements.Evaluate(s.db, snapshot)
+	return err
+}
+
+// Sweep queues a refresh for every user whose latest snapshot is dirty,
+// returning how many were queued. Pending refreshes only live in memory, so
+// this picks up the ones lost in a restart.
+func (s *Scheduler) Sweep() (int, error) {
+	userIDs, err := snapshots.DirtyUserIDs(s.db)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range userIDs {
+		s.Schedule(id)
+	}
+	return len(userIDs), nil
+}
+
+// PruneDeliveries deletes webhook delivery IDs older than DeliveryTTL,
+// returning how many were deleted
+func PruneDeliveries(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Where("created_at < ?", now.Add(-DeliveryTTL)).Delete(&models.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
+
+// Run sweeps dirty snapshots and prunes old webhook deliveries periodically,
+// starting right away; it never returns
+func (s *Scheduler) Run() {
+	for {
+		if queued, err := s.Sweep(); err != nil {
+			log.Printf("Failed to queue dirty GitHub snapshots: %v", err)
+		} else if queued > 0 {
+			log.Printf("Queued %d dirty GitHub snapshots for refresh", queued)
+		}
+		if pruned, err := PruneDeliveries(s.db, time.Now()); err != nil {
+			log.Printf("Failed to prune webhook deliveries: %v", err)
+		} else if pruned > 0 {
+			log.Printf("Pruned %d webhook deliveries", pruned)
+		}
+		time.Sleep(sweepInterval)
+	}
+}
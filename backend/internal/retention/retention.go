@@ -0,0 +1,239 @@
+package retention
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// DefaultGracePeriod is how long a deleted account can be restored before it is purged
+const DefaultGracePeriod = 30 * 24 * time.Hour
+
+// purgeInterval is how often accounts past their grace period are purged
+const purgeInterval = time.Hour
+
+// ErrNotRestorable is returned when no deleted account can be restored
+var ErrNotRestorable = errors.New("no restorable account")
+
+// ErrEmailInUse is returned when a new account took a deleted account's email
+var ErrEmailInUse = errors.New("email already registered to another account")
+
+// ErrSoleTeamOwner is returned when deleting an account would leave a team
+// with members but no owner
+var ErrSoleTeamOwner = errors.New("sole owner of a team with other members")
+
+// gracePeriod is how long deleted accounts are kept
+var gracePeriod = DefaultGracePeriod
+
+// SetGracePeriod sets how long deleted accounts can be restored
+func SetGracePeriod(period time.Duration) {
+	gracePeriod = period
+}
+
+// GracePeriod returns how long deleted accounts can be restored
+func GracePeriod() time.Duration {
+	return gracePeriod
+}
+
+// SoftDelete deletes a user's account, keeping it restorable for the grace
+// period. Forge tokens are cleared right away and must be re-entered after a
+// restore. Sole owners of teams with other members must hand them over first.
+func SoftDelete(db *gorm.DB, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := CheckTeamOwnership(tx, userID); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("github_token", "").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.GithubAccount{}).Where("user_id = ?", userID).Update("token", "").Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, userID).Error
+	})
+}
+
+// CheckTeamOwnership returns ErrSoleTeamOwner when the user is the only
+// owner of a team that has other active members
+func CheckTeamOwnership(db *gorm.DB, userID uint) error {
+	var soleOwned int64
+	err := db.Raw(`
+		SELECT COUNT(*) FROM team_members m
+		JOIN teams t ON t.id = m.team_id AND t.deleted_at IS NULL
+		WHERE m.user_id = ? AND m.role = ?
+		AND NOT EXISTS (
+			SELECT 1 FROM team_members o JOIN users u ON u.id = o.user_id AND u.deleted_at IS NULL
+			WHERE o.team_id = m.team_id AND o.user_id <> m.user_id AND o.role = ?
+		)
+		AND EXISTS (
+			SELECT 1 FROM team_members o JOIN users u ON u.id = o.user_id AND u.deleted_at IS NULL
+			WHERE o.team_id = m.team_id AND o.user_id <> m.user_id
+		)`, userID, models.TeamRoleOwner, models.TeamRoleOwner).Scan(&soleOwned).Error
+	if err != nil {
+		return err
+	}
+	if soleOwned > 0 {
+		return ErrSoleTeamOwner
+	}
+	return nil
+}
+
+// FindRestorable returns the most recently deleted account for an email that
+// is still within the grace period
+func FindRestorable(db *gorm.DB, email string) (*models.User, error) {
+	var user models.User
+	err := db.Unscoped().
+		Where("email = ? AND deleted_at IS NOT NULL AND deleted_at > ?", email, time.Now().Add(-gracePeriod)).
+		Order("deleted_at DESC").
+		First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotRestorable
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Restore reactivates a deleted account, unless its email now belongs to
+// another account
+func Restore(db *gorm.DB, user *models.User) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("email = ?", user.Email).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrEmailInUse
+		}
+
+		if err := tx.Unscoped().Model(user).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		user.DeletedAt = gorm.DeletedAt{}
+		return nil
+	})
+}
+
+// PurgeAt returns when a deleted account will be purged
+func PurgeAt(deletedAt time.Time) time.Time {
+	return deletedAt.Add(gracePeriod)
+}
+
+// Purge hard-deletes accounts deleted before the grace period along with all
+// of their data, returning how many were purged
+func Purge(db *gorm.DB) (int, error) {
+	var userIDs []uint
+	err := db.Unscoped().Model(&models.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", time.Now().Add(-gracePeriod)).
+		Pluck("id", &userIDs).Error
+	if err != nil || len(userIDs) == 0 {
+		return 0, err
+	}
+
+	// Export archives live on disk, so find them before their rows are deleted
+	var exports []models.DataExport
+	if err := db.Where("user_id IN ? AND path <> ''", userIDs).Find(&exports).Error; err != nil {
+		return 0, err
+	}
+
+	var emails []string
+	if err := db.Unscoped().Model(&models.User{}).Where("id IN ?", userIDs).Pluck("email", &emails).Error; err != nil {
+		return 0, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := handOverTeams(tx, userIDs); err != nil {
+			return err
+		}
+		// Invitations reference their inviter and name the invitee's email,
+		// unless the email now belongs to another active account
+		err := tx.Where("invited_by IN ? OR (email IN ? AND NOT EXISTS (?))", userIDs, emails,
+			tx.Model(&models.User{}).Select("1").Where("LOWER(users.email) = team_invites.email"),
+		).Delete(&models.TeamInvite{}).Error
+		if err != nil {
+			return err
+		}
+
+		// Achievements reference snapshots, so they go first
+		for _, model := range []interface{}{
+			&models.Achievement{},
+			&models.GithubSnapshot{},
+			&models.GithubAccount{},
+			&models.TeamMember{},
+			&models.DataExport{},
+			&models.ConfirmationToken{},
+		} {
+			if err := tx.Where("user_id IN ?", userIDs).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Where("id IN ?", userIDs).Delete(&models.User{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, record := range exports {
+		if err := os.Remove(record.Path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove data export %d: %v", record.ID, err)
+		}
+	}
+	return len(userIDs), nil
+}
+
+// handOverTeams keeps the teams of purged users owned. Teams left without an
+// owner pass to their longest-standing manager, or member when there is none,
+// preferring active accounts; teams left without members are deleted.
+func handOverTeams(tx *gorm.DB, userIDs []uint) error {
+	var teamIDs []uint
+	err := tx.Model(&models.TeamMember{}).Where("user_id IN ?", userIDs).Distinct().Pluck("team_id", &teamIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, teamID := range teamIDs {
+		var remaining []models.TeamMember
+		err := tx.Joins("JOIN users ON users.id = team_members.user_id").
+			Where("team_members.team_id = ? AND team_members.user_id NOT IN ?", teamID, userIDs).
+			Order("users.deleted_at IS NULL DESC, CASE team_members.role WHEN 'owner' THEN 0 WHEN 'manager' THEN 1 ELSE 2 END, team_members.created_at").
+			Find(&remaining).Error
+		if err != nil {
+			return err
+		}
+
+		if len(remaining) == 0 {
+			if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamInvite{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&models.Team{}, teamID).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		if heir := remaining[0]; heir.Role != models.TeamRoleOwner {
+			if err := tx.Model(&heir).Update("role", models.TeamRoleOwner).Error; err != nil {
+				return err
+			}
+			log.Printf("Transferred ownership of team %d to user %d", teamID, heir.UserID)
+		}
+	}
+	return nil
+}
+
+// Run purges expired accounts periodically; it never returns
+func Run(db *gorm.DB) {
+	for {
+		if purged, err := Purge(db); err != nil {
+			log.Printf("Failed to purge deleted accounts: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted accounts", purged)
+		}
+		time.Sleep(purgeInterval)
+	}
+}
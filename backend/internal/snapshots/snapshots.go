@@ -0,0 +1,184 @@
+package snapshots
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DailyRetention is how long every daily snapshot is kept. Older snapshots
+// are thinned to the last one of each month, which is enough for the yearly
+// windows and rank history.
+const DailyRetention = 90 * 24 * time.Hour
+
+// pruneInterval is how often old snapshots are pruned
+const pruneInterval = 24 * time.Hour
+
+// New builds today's snapshot of a user's GitHub statistics, keeping the
+// private part of the contribution counts in their own columns
+func New(userID uint, stats *github.UserProfileStats, rank github.RankInfo, now time.Time) (*models.GithubSnapshot, error) {
+	payload, err := json.Marshal(stats)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &models.GithubSnapshot{
+		UserID:       userID,
+		Day:          day(now),
+		Login:        stats.Login,
+		Commits:      stats.TotalCommits,
+		PullRequests: stats.TotalPullRequests,
+		Issues:       stats.TotalIssues,
+		Reviews:      stats.TotalReviews,
+		Stars:        stats.TotalStarsEarned,
+		Followers:    stats.Followers,
+		Score:        rank.Score,
+		Rank:         rank.Rank,
+		Stats:        payload,
+		CreatedAt:    now,
+	}
+	if stats.IncludesPrivate && stats.Private != nil {
+		snapshot.PrivateCommits = stats.Private.Commits + stats.Private.Restricted
+		snapshot.PrivatePullRequests = stats.Private.PullRequests
+		snapshot.PrivateIssues = stats.Private.Issues
+		snapshot.PrivateReviews = stats.Private.Reviews
+	}
+	return snapshot, nil
+}
+
+// Save stores the user's GitHub statistics as today's snapshot, replacing any
+// snapshot already taken today. Achievements awarded from a replaced snapshot
+// keep their own copy of the values they were awarded for.
+func Save(db *gorm.DB, userID uint, stats *github.UserProfileStats, rank github.RankInfo) (*models.GithubSnapshot, error) {
+	snapshot, err := New(userID, stats, rank, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "day"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"login", "commits", "pull_requests", "issues", "reviews", "stars", "followers",
+			"private_commits", "private_pull_requests", "private_issues", "private_reviews",
+			"score", "rank", "stats", "dirty", "created_at",
+		}),
+	}).Create(snapshot).Error
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Prune deletes snapshots that are no longer needed, returning how many were
+// deleted: older snapshots of the same day, and snapshots past the daily
+// retention that aren't the last of their month. Snapshots an achievement
+// was awarded from are kept.
+func Prune(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Exec(`
+		DELETE FROM github_snapshots s
+		WHERE (s.day IS NULL OR (s.created_at < ? AND s.id NOT IN (
+			SELECT DISTINCT ON (user_id, date_trunc('month', created_at)) id
+			FROM github_snapshots
+			WHERE day IS NOT NULL
+			ORDER BY user_id, date_trunc('month', created_at), created_at DESC
+		)))
+		AND NOT EXISTS (SELECT 1 FROM achievements a WHERE a.snapshot_id = s.id)`,
+		now.Add(-DailyRetention))
+	return result.RowsAffected, result.Error
+}
+
+// Run prunes old snapshots periodically; it never returns
+func Run(db *gorm.DB) {
+	for {
+		if pruned, err := Prune(db, time.Now()); err != nil {
+			log.Printf("Failed to prune GitHub snapshots: %v", err)
+		} else if pruned > 0 {
+			log.Printf("Pruned %d GitHub snapshots", pruned)
+		}
+		time.Sleep(pruneInterval)
+	}
+}
+
+// Latest returns the most recent snapshot for a user
+func Latest(db *gorm.DB, userID uint) (*models.GithubSnapshot, error) {
+	var snapshot models.GithubSnapshot
+	if err := db.Where("user_id = ?", userID).Order("created_at DESC").First(&snapshot).Error; err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// LatestForAll returns the most recent snapshot of every active user
+func LatestForAll(db *gorm.DB) ([]models.GithubSnapshot, error) {
+	var list []models.GithubSnapshot
+	err := db.Raw(`
+		SELECT DISTINCT ON (s.user_id) s.*
+		FROM github_snapshots s
+		JOIN users u ON u.id = s.user_id AND u.deleted_at IS NULL
+		ORDER BY s.user_id, s.created_at DESC`).Scan(&list).Error
+	return list, err
+}
+
+// MarkDirty flags the latest snapshot of each user as out of date
+func MarkDirty(db *gorm.DB, userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return db.Exec(`
+		UPDATE github_snapshots SET dirty = true
+		WHERE id IN (
+			SELECT DISTINCT ON (user_id) id
+			FROM github_snapshots
+			WHERE user_id IN ?
+			ORDER BY user_id, created_at DESC
+		)`, userIDs).Error
+}
+
+// day returns the UTC date a snapshot taken at t belongs to
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// DirtyUserIDs returns the active users whose latest snapshot is out of date
+func DirtyUserIDs(db *gorm.DB) ([]uint, error) {
+	var ids []uint
+	err := db.Raw(`
+		SELECT user_id FROM (
+			SELECT DISTINCT ON (s.user_id) s.user_id, s.dirty
+			FROM github_snapshots s
+			JOIN users u ON u.id = s.user_id AND u.deleted_at IS NULL
+			ORDER BY s.user_id, s.created_at DESC
+		) latest
+		WHERE dirty`).Scan(&ids).Error
+	return ids, err
+}
+
+// Decode returns the full profile stats stored in a snapshot
+func Decode(snapshot *models.GithubSnapshot) (*github.UserProfileStats, error) {
+	var stats github.UserProfileStats
+	if err := json.Unmarshal(snapshot.Stats, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Summary returns the scored metrics of a snapshot without decoding the full
+// payload. Private contributions are left out, since summaries feed the
+// leaderboards, team stats and percentiles other users see.
+func Summary(snapshot models.GithubSnapshot) github.UserProfileStats {
+	return github.UserProfileStats{
+		Login:             snapshot.Login,
+		TotalCommits:      nonNegative(snapshot.Commits - snapshot.PrivateCommits),
+		TotalPullRequests: nonNegative(snapshot.PullRequests - snapshot.PrivatePullRequests),
+		TotalIssues:       nonNegative(snapshot.Issues - snapshot.PrivateIssues),
+		TotalReviews:      nonNegative(snapshot.Reviews - snapshot.PrivateReviews),
+		TotalStarsEarned:  snapshot.Stars,
+		Followers:         snapshot.Followers,
+	}
+}
@@ -0,0 +1,181 @@
+package snapshots
+
+import (
+	"time"
+
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Time windows supported for snapshot comparisons
+const (
+	WindowAll   = "all"
+	WindowWeek  = "week"
+	WindowMonth = "month"
+	WindowYear  = "year"
+)
+
+// WindowStart returns the start of a time window relative to now. The zero
+// time is returned for WindowAll and ok is false for unknown windows.
+func WindowStart(window string, now time.Time) (start time.Time, ok bool) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch window {
+	case "", WindowAll:
+		return time.Time{}, true
+	case WindowWeek:
+		// Weeks start on Monday
+		offset := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -offset), true
+	case WindowMonth:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), true
+	case WindowYear:
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// LatestForUsers returns the most recent snapshot for each of the given users
+func LatestForUsers(db *gorm.DB, userIDs []uint) ([]models.GithubSnapshot, error) {
+	var list []models.GithubSnapshot
+	if len(userIDs) == 0 {
+		return list, nil
+	}
+	err := db.Raw(`
+		SELECT DISTINCT ON (user_id) *
+		FROM github_snapshots
+		WHERE user_id IN ?
+		ORDER BY user_id, created_at DESC`, userIDs).Scan(&list).Error
+	return list, err
+}
+
+// LatestBefore returns, for each user, the most recent snapshot taken before t
+func LatestBefore(db *gorm.DB, userIDs []uint, t time.Time) (map[uint]models.GithubSnapshot, error) {
+	var list []models.GithubSnapshot
+	if len(userIDs) > 0 {
+		err := db.Raw(`
+			SELECT DISTINCT ON (user_id) *
+			FROM github_snapshots
+			WHERE user_id IN ? AND created_at < ?
+			ORDER BY user_id, created_at DESC`, userIDs, t).Scan(&list).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return byUser(list), nil
+}
+
+// EarliestSince returns, for each user, the first snapshot taken at or after t
+func EarliestSince(db *gorm.DB, userIDs []uint, t time.Time) (map[uint]models.GithubSnapshot, error) {
+	var list []models.GithubSnapshot
+	if len(userIDs) > 0 {
+		err := db.Raw(`
+			SELECT DISTINCT ON (user_id) *
+			FROM github_snapshots
+			WHERE user_id IN ? AND created_at >= ?
+			ORDER BY user_id, created_at ASC`, userIDs, t).Scan(&list).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	return byUser(list), nil
+}
+
+// ForWindow returns each user's activity within the window starting at
+// windowStart, keyed by user ID. The zero time returns the latest totals.
+// Users without any snapshot are left out.
+func ForWindow(db *gorm.DB, userIDs []uint, windowStart time.Time) (map[uint]github.UserProfileStats, error) {
+	latest, err := LatestForUsers(db, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load baselines for windowed stats
+	var before, since map[uint]models.GithubSnapshot
+	if !windowStart.IsZero() {
+		if before, err = LatestBefore(db, userIDs, windowStart); err != nil {
+			return nil, err
+		}
+		if since, err = EarliestSince(db, userIDs, windowStart); err != nil {
+			return nil, err
+		}
+	}
+
+	stats := make(map[uint]github.UserProfileStats, len(latest))
+	for _, snap := range latest {
+		var baseline *models.GithubSnapshot
+		if b, ok := before[snap.UserID]; ok {
+			baseline = &b
+		} else if b, ok := since[snap.UserID]; ok {
+			// No snapshot before the window; only count activity we observed
+			baseline = &b
+		}
+		stats[snap.UserID] = Delta(snap, baseline, windowStart)
+	}
+	return stats, nil
+}
+
+// Delta returns the activity between a baseline and the latest snapshot.
+//
+// Contribution counts (commits, PRs, issues, reviews) are year-to-date
+// counters that reset every January, so a window starting on or before the
+// start of the latest snapshot's year uses a zero baseline for them. Stars and
+// followers are cumulative and always subtract the baseline. A nil baseline
+// means no snapshot exists before the window, and the latest values are used
+// as-is for year-to-date counters.
+func Delta(latest models.GithubSnapshot, baseline *models.GithubSnapshot, windowStart time.Time) github.UserProfileStats {
+	current := Summary(latest)
+	if windowStart.IsZero() {
+		return current
+	}
+
+	var base github.UserProfileStats
+	if baseline != nil {
+		base = Summary(*baseline)
+	}
+
+	startOfYear := time.Date(latest.CreatedAt.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	yearToDateBaseline := baseline != nil &&
+		windowStart.After(startOfYear) &&
+		baseline.CreatedAt.Year() == latest.CreatedAt.Year()
+
+	delta := github.UserProfileStats{
+		Login:            current.Login,
+		TotalStarsEarned: nonNegative(current.TotalStarsEarned - base.TotalStarsEarned),
+		Followers:        nonNegative(current.Followers - base.Followers),
+	}
+
+	if yearToDateBaseline {
+		delta.TotalCommits = nonNegative(current.TotalCommits - base.TotalCommits)
+		delta.TotalPullRequests = nonNegative(current.TotalPullRequests - base.TotalPullRequests)
+		delta.TotalIssues = nonNegative(current.TotalIssues - base.TotalIssues)
+		delta.TotalReviews = nonNegative(current.TotalReviews - base.TotalReviews)
+	} else {
+		delta.TotalCommits = current.TotalCommits
+		delta.TotalPullRequests = current.TotalPullRequests
+		delta.TotalIssues = current.TotalIssues
+		delta.TotalReviews = current.TotalReviews
+	}
+
+	return delta
+}
+
+// byUser indexes snapshots by user ID
+func byUser(list []models.GithubSnapshot) map[uint]models.GithubSnapshot {
+	m := make(map[uint]models.GithubSnapshot, len(list))
+	for _, s := range list {
+		m[s.UserID] = s
+	}
+	return m
+}
+
+// nonNegative clamps negative values to zero
+func nonNegative(v int) int {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
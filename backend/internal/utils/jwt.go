@@ -17,13 +17,16 @@ func InitJWT(secret string) {
 // Claims represents the JWT claims structure
 type Claims struct {
 	UserID uint `json:"user_id"`
+	// AuthTime is when the user last entered their password
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken creates a new JWT token for a user
+// GenerateToken creates a new JWT token for a user who just authenticated
 func GenerateToken(userID uint) (string, error) {
 	claims := &Claims{
-		UserID: userID,
+		UserID:   userID,
+		AuthTime: jwt.NewNumericDate(time.Now()),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
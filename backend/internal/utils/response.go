@@ -9,6 +9,7 @@ import (
 type ErrorResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
+	Code    string `json:"code,omitempty"` // Machine-readable reason, when clients need to act on it
 }
 
 // SuccessResponse represents a success response structure
@@ -33,6 +34,15 @@ func RespondError(w http.ResponseWriter, status int, message string) {
 	})
 }
 
+// RespondErrorCode sends an error response with a machine-readable code
+func RespondErrorCode(w http.ResponseWriter, status int, code, message string) {
+	RespondJSON(w, status, ErrorResponse{
+		Success: false,
+		Message: message,
+		Code:    code,
+	})
+}
+
 // RespondSuccess sends a success response
 func RespondSuccess(w http.ResponseWriter, data interface{}) {
 	RespondJSON(w, http.StatusOK, SuccessResponse{
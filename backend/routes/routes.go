@@ -1,18 +1,26 @@
 package routes
 
 import (
+	"github.com/amilcar-vasquez/auth-service/backend/internal/dataexport"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/github"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/handlers"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/mailer"
 	"github.com/amilcar-vasquez/auth-service/backend/internal/middleware"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/providers"
+	"github.com/amilcar-vasquez/auth-service/backend/internal/refresh"
 	"github.com/go-chi/chi/v5"
 	"gorm.io/gorm"
 )
 
 // SetupRoutes configures all application routes
-func SetupRoutes(r *chi.Mux, db *gorm.DB) {
+func SetupRoutes(r *chi.Mux, db *gorm.DB, registry providers.Registry, scheduler *refresh.Scheduler, exporter *dataexport.Exporter, mail mailer.Mailer, webhookSecret string) {
 
 	// Initialize handlers
 	authHandler := &handlers.AuthHandler{DB: db}
-	userHandler := &handlers.UserHandler{DB: db}
+	userHandler := &handlers.UserHandler{DB: db, Mailer: mail}
+
+	// Cache for looking up arbitrary GitHub users
+	profileCache := github.NewProfileCache(github.DefaultProfileCacheTTL)
 
 	// Public routes
 	r.Route("/api", func(r chi.Router) {
@@ -22,6 +30,23 @@ func SetupRoutes(r *chi.Mux, db *gorm.DB) {
 		// Authentication routes (public)
 		r.Post("/register", authHandler.Register)
 		r.Post("/login", authHandler.Login)
+		r.Post("/account/restore", authHandler.RestoreAccount)
+
+		// Public profiles
+		r.Get("/users/{handle}", handlers.GetPublicProfile(db))
+
+		// Embeddable cards
+		r.Get("/cards/{handle}/rank.svg", handlers.GetRankCard(db))
+		r.Get("/cards/{handle}/stats.svg", handlers.GetStatsCard(db))
+		r.Get("/cards/{handle}/languages.svg", handlers.GetLanguagesCard(db))
+		r.Get("/cards/{handle}/heatmap.{format:svg|png}", handlers.GetHeatmap(db))
+		r.Get("/cards/{handle}/contributions.ics", handlers.GetContributionFeed(db))
+
+		// Personal data export downloads (authorized by a signed link)
+		r.Get("/exports/{id}/download", handlers.DownloadDataExport(exporter))
+
+		// GitHub webhooks (authenticated by signature)
+		r.Post("/webhooks/github", handlers.GithubWebhook(db, webhookSecret, scheduler))
 
 		// Protected routes
 		r.Group(func(r chi.Router) {
@@ -30,11 +55,54 @@ func SetupRoutes(r *chi.Mux, db *gorm.DB) {
 			// User profile routes
 			r.Get("/profile", userHandler.GetProfile)
 			r.Put("/profile", userHandler.UpdateProfile)
-			r.Delete("/profile", userHandler.DeleteProfile)
+			r.Put("/profile/privacy", userHandler.UpdatePrivacy)
+			r.Get("/profile/achievements", userHandler.GetAchievements)
+			r.Post("/profile/export", handlers.RequestDataExport(exporter))
+			r.Get("/profile/export/{id}", handlers.GetDataExport(exporter))
+
+			// Re-authentication for sudo mode
+			r.Post("/auth/reauthenticate", authHandler.Reauthenticate)
+
+			// Sensitive routes require recent authentication
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireRecentAuth)
+
+				r.Post("/profile/deletion", userHandler.RequestAccountDeletion)
+				r.Delete("/profile", userHandler.DeleteProfile)
+				r.Put("/github/credentials", handlers.UpdateGithubCredentials(db))
+				r.Post("/github/accounts", handlers.AddGithubAccount(db))
+				r.Delete("/github/accounts/{id}", handlers.DeleteGithubAccount(db))
+			})
 
 			// GitHub integration routes
-			r.Get("/github/profile", handlers.GetGithubProfile(db))
-			r.Put("/github/credentials", handlers.UpdateGithubCredentials(db))
+			r.Get("/github/profile", handlers.GetGithubProfile(db, registry))
+			r.Get("/github/accounts", handlers.ListGithubAccounts(db))
+			r.Get("/github/compare", handlers.CompareGithubUsers(db, registry, profileCache))
+			r.Get("/github/users/{login}", handlers.GetGithubUser(db, registry, profileCache))
+			r.Get("/github/repos", handlers.GetGithubRepos(db, registry))
+
+			// Data export routes
+			r.Get("/export/contributions.{format:csv|jsonl|ics}", handlers.ExportContributions(db))
+			r.Get("/export/rank-history.{format:csv|jsonl}", handlers.ExportRankHistory(db))
+
+			// Leaderboard routes
+			r.Get("/leaderboard", handlers.GetLeaderboard(db))
+
+			// Team routes
+			r.Get("/teams", handlers.ListTeams(db))
+			r.Post("/teams", handlers.CreateTeam(db))
+			r.Get("/teams/{id}", handlers.GetTeam(db))
+			r.Put("/teams/{id}", handlers.UpdateTeam(db))
+			r.Delete("/teams/{id}", handlers.DeleteTeam(db))
+			r.Get("/teams/{id}/stats", handlers.GetTeamStats(db))
+			r.Get("/teams/{id}/invites", handlers.ListTeamInvites(db))
+			r.Post("/teams/{id}/invites", handlers.InviteTeamMember(db, mail))
+			r.Delete("/teams/{id}/invites/{inviteID}", handlers.RevokeTeamInvite(db))
+			r.Put("/teams/{id}/members/{userID}", handlers.UpdateTeamMember(db))
+			r.Delete("/teams/{id}/members/{userID}", handlers.RemoveTeamMember(db))
+			r.Get("/team-invites", handlers.ListMyTeamInvites(db))
+			r.Post("/team-invites/{id}/accept", handlers.AcceptTeamInvite(db))
+			r.Delete("/team-invites/{id}", handlers.DeclineTeamInvite(db))
 		})
 	})
 }
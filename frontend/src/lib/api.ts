@@ -108,12 +108,44 @@ export async function put<T = any>(endpoint: string, body?: any): Promise<ApiRes
 /**
  * DELETE request
  */
-export async function del<T = any>(endpoint: string): Promise<ApiResponse<T>> {
+export async function del<T = any>(endpoint: string, body?: any): Promise<ApiResponse<T>> {
 	return fetchWithAuth(endpoint, {
-		method: 'DELETE'
+		method: 'DELETE',
+		body: body ? JSON.stringify(body) : undefined
 	});
 }
 
+/**
+ * Whether a request failed because the user must confirm their password first
+ */
+export function isReauthenticationRequired(error: unknown): boolean {
+	return error instanceof ApiError && error.status === 403 && error.response?.code === 'reauthentication_required';
+}
+
+/**
+ * GET a file download, such as a data export
+ */
+export async function download(endpoint: string): Promise<Blob> {
+	const token = getToken();
+	const headers: Record<string, string> = {};
+	if (token) {
+		headers['Authorization'] = `Bearer ${token}`;
+	}
+
+	let response: Response;
+	try {
+		response = await fetch(`${API_BASE_URL}${endpoint}`, { headers });
+	} catch (error) {
+		throw new ApiError(error instanceof Error ? error.message : 'Network error', 0);
+	}
+
+	if (!response.ok) {
+		const data = await response.json().catch(() => undefined);
+		throw new ApiError(data?.message || 'Download failed', response.status, data);
+	}
+	return response.blob();
+}
+
 /**
  * Health check endpoint
  */
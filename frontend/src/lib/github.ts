@@ -10,10 +10,48 @@ export interface GitHubProfileStats {
 	total_issues: number;
 	total_reviews: number;
 	total_stars_earned: number;
+	contributed_stars: number;
+	stars_truncated: boolean;
 	followers: number;
 	contribution_calendar: ContributionCalendar;
 	pinned_repositories: Repository[];
 	total_public_repositories: number;
+	period: TimeRange;
+	top_languages: LanguageStat[];
+	rate_limit?: RateLimit;
+	includes_private: boolean;
+	private?: PrivateContributions;
+}
+
+/** Contributions to private repositories counted in a profile's totals */
+export interface PrivateContributions {
+	commits: number;
+	pull_requests: number;
+	issues: number;
+	reviews: number;
+	/** Contributions the token can't see; counted as commits */
+	restricted: number;
+	days?: Record<string, number>;
+}
+
+export interface RateLimit {
+	limit: number;
+	remaining: number;
+	cost: number;
+	reset_at: string;
+}
+
+export interface LanguageStat {
+	name: string;
+	color: string;
+	bytes: number;
+	share_percent: number;
+	repo_count: number;
+}
+
+export interface TimeRange {
+	from: string;
+	to: string;
 }
 
 export interface ContributionCalendar {
@@ -38,6 +76,41 @@ export interface Repository {
 	fork_count: number;
 	primary_language: Language;
 	url: string;
+	topics: string[];
+	license?: string;
+	pushed_at: string | null;
+	open_issues: number;
+	open_pull_requests: number;
+	releases: number;
+	/** Commits to the default branch in the last 30 days */
+	recent_commits: number;
+	is_fork: boolean;
+	is_archived: boolean;
+}
+
+export type RepositorySort = 'stars' | 'forks' | 'issues' | 'activity' | 'pushed' | 'name';
+
+export interface RepositoryQuery {
+	sort?: RepositorySort;
+	order?: 'asc' | 'desc';
+	language?: string;
+	topic?: string;
+	q?: string;
+	min_stars?: number;
+	include_forks?: boolean;
+	include_archived?: boolean;
+	page?: number;
+	per_page?: number;
+}
+
+export interface RepositoriesResponse {
+	sort: RepositorySort;
+	order: 'asc' | 'desc';
+	page: number;
+	per_page: number;
+	total: number;
+	truncated: boolean;
+	repositories: Repository[];
 }
 
 export interface Language {
@@ -51,18 +124,156 @@ export interface RankInfo {
 	next_rank?: string;
 	next_rank_threshold?: number;
 	progress_percent: number;
+	breakdown: MetricBreakdown[];
+	suggestions?: RankSuggestion[];
+}
+
+export interface MetricBreakdown {
+	metric: string;
+	value: number;
+	weight: number;
+	points: number;
+	share_percent: number;
 }
 
+export interface RankSuggestion {
+	metric: string;
+	amount_needed: number;
+	message: string;
+}
+
+export interface PercentileInfo {
+	overall: number;
+	metrics: Record<string, number>;
+	sample_size: number;
+}
+
+export interface ContributionAnalytics {
+	current_streak: number;
+	longest_streak: number;
+	most_active_weekday: string;
+	average_daily_contributions: number;
+	busiest_month: string;
+	busiest_month_contributions: number;
+	this_week_contributions: number;
+	last_week_contributions: number;
+	week_over_week_change_percent: number | null;
+}
+
+export type RankMode = 'absolute' | 'curve';
+
 export interface GitHubProfileResponse {
 	profile: GitHubProfileStats;
 	rank: RankInfo;
+	percentile: PercentileInfo | null;
+	analytics: ContributionAnalytics;
+	accounts: GitHubAccount[];
+	/** True when GitHub's rate limit was hit and the last snapshot is served */
+	stale: boolean;
+	snapshot_at?: string;
+	retry_at?: string;
+	/** Achievements first met by this request's snapshot */
+	new_achievements?: AwardedAchievement[];
+}
+
+export interface AwardedAchievement {
+	id: number;
+	user_id: number;
+	rule_id: string;
+	snapshot_id: number;
+	awarded_at: string;
+}
+
+export interface Achievement {
+	id: string;
+	name: string;
+	description: string;
+	metric: string;
+	threshold: number;
+	window_days?: number;
+	value: number;
+	earned: boolean;
+	awarded_at: string | null;
+}
+
+export interface GitHubUserLookup {
+	profile: GitHubProfileStats;
+	rank: RankInfo;
+	analytics: ContributionAnalytics;
+	cached: boolean;
+	fetched_at: string;
+}
+
+export interface ComparedUser {
+	login: string;
+	source: 'snapshot' | 'github';
+	user_id?: number;
+	profile: GitHubProfileStats;
+	rank: RankInfo;
+	values: Record<string, number>;
+	/** Difference from the first compared user */
+	deltas: Record<string, number>;
+	/** Values scaled to 0-100 against the highest in the comparison */
+	radar: Record<string, number>;
+}
+
+export interface CompareResponse {
+	metrics: string[];
+	baseline: string;
+	users: ComparedUser[];
+}
+
+export type AccountProvider = 'github' | 'gitlab' | 'gitea';
+
+export type ExportFormat = 'csv' | 'jsonl';
+
+export interface DataExport {
+	id: number;
+	user_id: number;
+	status: 'pending' | 'ready' | 'failed';
+	error?: string;
+	size: number;
+	created_at: string;
+	completed_at?: string;
+	expires_at?: string;
+	/** Signed link relative to the API base URL, set once the archive is ready */
+	download_path?: string;
+}
+
+export interface GitHubAccount {
+	id: number;
+	user_id: number;
+	provider: AccountProvider;
+	host: string;
+	username: string;
+	created_at: string;
+	updated_at: string;
+}
+
+export interface GitHubProfileQuery {
+	mode?: RankMode;
+	window?: '30d' | '90d' | '365d';
+	year?: number;
+	from_year?: number;
+	to_year?: number;
+	include_contributed?: boolean;
+	ignore_languages?: string;
 }
 
 /**
  * Fetch GitHub profile stats and rank for the authenticated user
  */
-export async function fetchGithubProfile(): Promise<GitHubProfileResponse> {
-	const response = await api.get<GitHubProfileResponse>('/github/profile');
+export async function fetchGithubProfile(
+	query: GitHubProfileQuery = {}
+): Promise<GitHubProfileResponse> {
+	const params = new URLSearchParams();
+	for (const [key, value] of Object.entries(query)) {
+		if (value !== undefined) params.set(key, String(value));
+	}
+	const search = params.toString();
+	const response = await api.get<GitHubProfileResponse>(
+		search ? `/github/profile?${search}` : '/github/profile'
+	);
 	return response.data!;
 }
 
@@ -71,10 +282,117 @@ export async function fetchGithubProfile(): Promise<GitHubProfileResponse> {
  */
 export async function updateGithubCredentials(
 	githubUsername: string,
-	githubToken: string
+	githubToken: string,
+	githubHost?: string
 ): Promise<void> {
 	await api.put('/github/credentials', {
 		github_username: githubUsername,
-		github_token: githubToken
+		github_token: githubToken,
+		github_host: githubHost
+	});
+}
+
+/**
+ * List connected GitHub accounts, primary account first
+ */
+export async function fetchGithubAccounts(): Promise<GitHubAccount[]> {
+	const response = await api.get<GitHubAccount[]>('/github/accounts');
+	return response.data!;
+}
+
+/**
+ * Connect an additional GitHub, GitHub Enterprise, GitLab or Gitea account
+ */
+export async function addGithubAccount(
+	provider: AccountProvider,
+	host: string,
+	username: string,
+	token: string
+): Promise<GitHubAccount> {
+	const response = await api.post<GitHubAccount>('/github/accounts', {
+		provider,
+		host,
+		username,
+		token
 	});
+	return response.data!;
+}
+
+/**
+ * Disconnect an additional GitHub account
+ */
+export async function deleteGithubAccount(id: number): Promise<void> {
+	await api.del(`/github/accounts/${id}`);
+}
+
+/**
+ * List achievements and progress towards them
+ */
+export async function fetchAchievements(): Promise<Achievement[]> {
+	const response = await api.get<Achievement[]>('/profile/achievements');
+	return response.data!;
+}
+
+/**
+ * Compare 2-5 developers side by side by GitHub login
+ */
+export async function compareGithubUsers(logins: string[]): Promise<CompareResponse> {
+	const params = new URLSearchParams({ users: logins.join(',') });
+	const response = await api.get<CompareResponse>(`/github/compare?${params}`);
+	return response.data!;
+}
+
+/**
+ * Look up stats and rank for any GitHub login using your own token
+ */
+export async function fetchGithubUser(login: string): Promise<GitHubUserLookup> {
+	const response = await api.get<GitHubUserLookup>(`/github/users/${encodeURIComponent(login)}`);
+	return response.data!;
+}
+
+/**
+ * List your public repositories with details, filtered and sorted
+ */
+export async function fetchGithubRepos(query: RepositoryQuery = {}): Promise<RepositoriesResponse> {
+	const params = new URLSearchParams();
+	for (const [key, value] of Object.entries(query)) {
+		if (value !== undefined && value !== '') {
+			params.set(key, String(value));
+		}
+	}
+	const search = params.toString();
+	const response = await api.get<RepositoriesResponse>(
+		search ? `/github/repos?${search}` : '/github/repos'
+	);
+	return response.data!;
+}
+
+/**
+ * Download your contribution calendar as CSV, JSON Lines or an iCalendar file
+ */
+export async function exportContributions(format: ExportFormat | 'ics'): Promise<Blob> {
+	return api.download(`/export/contributions.${format}`);
+}
+
+/**
+ * Download your rank and stats history as CSV or JSON Lines
+ */
+export async function exportRankHistory(format: ExportFormat): Promise<Blob> {
+	return api.download(`/export/rank-history.${format}`);
+}
+
+/**
+ * Start assembling an archive of everything stored about you
+ */
+export async function requestDataExport(): Promise<DataExport> {
+	const response = await api.post<DataExport>('/profile/export');
+	return response.data!;
+}
+
+/**
+ * Check whether a data export is ready to download
+ */
+export async function fetchDataExport(id: number): Promise<DataExport> {
+	const response = await api.get<DataExport>(`/profile/export/${id}`);
+	return response.data!;
 }
@@ -209,13 +209,42 @@ function createAuthStore() {
 		},
 
 		/**
-		 * Delete user account
+		 * Confirm the password to unlock sensitive actions for a few minutes
 		 */
-		async deleteAccount(): Promise<void> {
+		async reauthenticate(password: string): Promise<void> {
+			const response = await api.post<{ token: string; user: User }>('/auth/reauthenticate', {
+				password
+			});
+
+			if (response.success && response.data) {
+				const { token, user } = response.data;
+
+				// Save to localStorage
+				if (browser) {
+					localStorage.setItem('auth_token', token);
+					localStorage.setItem('auth_user', JSON.stringify(user));
+				}
+
+				// Update store
+				update((state) => ({ ...state, token, user }));
+			}
+		},
+
+		/**
+		 * Email a confirmation code for deleting the account
+		 */
+		async requestAccountDeletion(): Promise<void> {
+			await api.post('/profile/deletion');
+		},
+
+		/**
+		 * Delete user account using the emailed confirmation code
+		 */
+		async deleteAccount(confirmationToken: string): Promise<void> {
 			update((state) => ({ ...state, loading: true, error: null }));
 
 			try {
-				await api.del('/profile');
+				await api.del('/profile', { confirmation_token: confirmationToken });
 
 				// Clear localStorage and reset store
 				if (browser) {
@@ -234,6 +263,41 @@ function createAuthStore() {
 			}
 		},
 
+		/**
+		 * Restore an account deleted within the grace period and sign in
+		 */
+		async restoreAccount(email: string, password: string): Promise<void> {
+			update((state) => ({ ...state, loading: true, error: null }));
+
+			try {
+				const response = await api.post<{ token: string; user: User }>('/account/restore', {
+					email,
+					password
+				});
+
+				if (response.success && response.data) {
+					const { token, user } = response.data;
+
+					// Save to localStorage
+					if (browser) {
+						localStorage.setItem('auth_token', token);
+						localStorage.setItem('auth_user', JSON.stringify(user));
+					}
+
+					// Update store
+					set({ token, user, loading: false, error: null });
+
+					// Redirect to profile
+					goto('/profile');
+				}
+			} catch (error) {
+				const errorMessage =
+					error instanceof api.ApiError ? error.message : 'Failed to restore account';
+				update((state) => ({ ...state, loading: false, error: errorMessage }));
+				throw error;
+			}
+		},
+
 		/**
 		 * Clear error message
 		 */